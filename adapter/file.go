@@ -60,7 +60,6 @@ func (u *UploadFile) FileConvertToByteReader() (*bytes.Reader, error) {
 	bs := make([]byte, size)
 	_, err = bufio.NewReader(src).Read(bs)
 	if err != nil && err != io.EOF {
-		fmt.Println(err)
 		return nil, err
 	}
 	return bytes.NewReader(bs), nil
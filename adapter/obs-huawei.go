@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"context"
 	"fmt"
 	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
 	"scylla/pkg/config"
@@ -23,9 +24,7 @@ func InitObsAdapter(conf config.ObsHuawei) (*ObsAdapterImpl, error) {
 
 	obsClient, err := obs.New(conf.Ak, conf.Sk, endpoint)
 	if err != nil {
-		fmt.Printf("Create obsClient error, errMsg: %s", err.Error())
-		obsClient.Close()
-		return nil, err
+		return nil, fmt.Errorf("create obs client: %w", err)
 	}
 
 	return &ObsAdapterImpl{Obsc: obsClient, FileBaseUrl: fileBaseUrl, Bucket: conf.Bucket}, nil
@@ -109,3 +108,23 @@ func (o *ObsAdapterImpl) Delete(key string) error {
 
 	return nil
 }
+
+// Ping checks that the bucket is reachable with the configured credentials.
+func (o *ObsAdapterImpl) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := o.Obsc.HeadBucket(o.Bucket)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (o *ObsAdapterImpl) Close() {
+	o.Obsc.Close()
+}
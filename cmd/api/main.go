@@ -1,18 +1,31 @@
 package main
 
 import (
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"scylla/dto"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"scylla/adapter"
 	"scylla/handler"
+	"scylla/pkg/cache"
 	"scylla/pkg/config"
 	"scylla/pkg/connection"
 	"scylla/pkg/exception"
+	"scylla/pkg/health"
+	"scylla/pkg/lifecycle"
+	"scylla/pkg/logger"
+	"scylla/pkg/metrics"
+	"scylla/pkg/middleware"
+	"scylla/pkg/query"
+	"scylla/pkg/tracing"
 	"scylla/pkg/utils"
 	"scylla/repository"
 	"scylla/service"
+	"time"
+	_ "time/tzdata" // timezones for X-Timezone without relying on the image
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/swaggo/fiber-swagger"
@@ -30,14 +43,66 @@ import (
 
 func main() {
 	//config
-	conf := config.Get()
+	conf, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	//logger
+	log := logger.Setup(conf.Log, os.Stdout)
+
+	//lifecycle
+	lc := lifecycle.New()
+
+	//tracing
+	shutdownTracing, err := tracing.Setup(conf.Tracing)
+	if err != nil {
+		log.Error("tracing setup failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	lc.OnStop("tracing", shutdownTracing)
+
+	healthRegistry := health.NewRegistry(conf.Health.Timeout, lc.Stopping)
 
 	//database
-	db := connection.GetDatabase(conf.Database)
+	db := connection.GetDatabase(conf.Database, log)
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+	healthRegistry.Register("database", sqlDB.PingContext)
+	lc.OnStop("database", func(ctx context.Context) error {
+		return sqlDB.Close()
+	})
+
+	//storage
+	if conf.Obs.Endpoint != "" {
+		obsAdapter, err := adapter.InitObsAdapter(conf.Obs)
+		if err != nil {
+			panic(err)
+		}
+		if conf.Health.CheckObs {
+			healthRegistry.Register("obs", obsAdapter.Ping)
+		}
+		lc.OnStop("obs", func(ctx context.Context) error {
+			obsAdapter.Close()
+			return nil
+		})
+	}
+	if conf.Health.CheckKong {
+		healthRegistry.Register("kong", health.HTTP(http.DefaultClient, conf.Kong.Url))
+	}
 
 	//Validate
 	validate := utils.InitializeValidator()
 
+	// the config validates the name, so this only fails without tzdata
+	defaultLocation, err := time.LoadLocation(conf.Time.DefaultTimezone)
+	if err != nil {
+		panic(err)
+	}
+
 	// environment swagger
 	if conf.Swagger.Mode != "dev" {
 		docs.SwaggerInfo.Host = conf.Swagger.Host
@@ -47,40 +112,92 @@ func main() {
 		docs.SwaggerInfo.BasePath = "/api/v1"
 	}
 	// init repository
-	customerRepo := repository.NewCustomerRepoImpl(db)
+	cursors := query.NewCursors(conf.Pagination.CursorSecret)
+	customerRepo := repository.NewCustomerRepoImpl(db, cursors, log)
+	vehicleRepo := repository.NewVehicleRepoImpl(db, log)
+	customerVehicleRepo := repository.NewCustomerVehicleRepoImpl(db, log)
 	// init service
-	customerService := service.NewCustomerServiceImpl(customerRepo, validate)
-	dmsService := service.NewDmsServiceImpl()
+	dmsCacheMetrics := &cache.Metrics{}
+	metrics.RegisterCache("dms", dmsCacheMetrics)
+	dmsUpstream := service.NewDmsServiceMetrics(service.NewDmsServiceImpl(conf.Kong, log))
+	dmsCacheStore := cache.NewMemoryStore(time.Minute, conf.DmsCache.MaxEntries)
+	lc.OnStop("dms cache", func(ctx context.Context) error {
+		return dmsCacheStore.Close()
+	})
+	// DMS sends times without an offset in the same default timezone
+	dmsService := service.NewDmsServiceTimezone(service.NewDmsServiceCache(dmsUpstream, dmsCacheStore, dmsCacheMetrics, conf.DmsCache, log), defaultLocation)
+	vehicleSyncService := service.NewVehicleSyncServiceImpl(vehicleRepo, dmsUpstream, conf.VehicleSync, log)
+	customerService := service.NewCustomerServiceImpl(customerRepo, customerVehicleRepo, dmsService, validate, log)
 	// init handler
 	customerHandler := handler.NewCustomerHandler(customerService)
-	dmsHandler := handler.NewDmsHandler(dmsService)
+	dmsHandler := handler.NewDmsHandler(dmsService, vehicleSyncService, validate)
+	healthHandler := handler.NewHealthHandler(healthRegistry)
 
 	app := fiber.New(fiber.Config{
-		ErrorHandler: exception.ExceptionHandlers,
+		ErrorHandler: exception.ExceptionHandlers(validate),
 	})
+	//tracing comes first so panics recovered below end up on the span; its
+	//trace id doubles as the request id
+	if conf.Tracing.Exporter != tracing.ExporterNone {
+		app.Use(tracing.Middleware("/healthz", "/readyz", "/metrics"))
+	} else {
+		app.Use(requestid.New())
+	}
 	app.Use(recover.New())
-	app.Use(requestid.New())
-	app.Use(cors.New())
-	app.Use(logger.New(logger.Config{
-		Format: "[${locals:requestid}] ${ip} - ${method} ${status} ${path} - ${latency}\n",
-	}))
+	//probes and scrapes skip cors, rate limiting and access logs
+	healthHandler.Route(app)
+	app.Get("/metrics", metrics.Handler())
+	app.Use(metrics.HTTP())
+	app.Use(middleware.Cors())
+	app.Use(middleware.RateLimit())
+	app.Use(middleware.Tenant(conf.Kong))
+	app.Use(middleware.Database(db))
+	app.Use(middleware.Timezone(defaultLocation))
+	app.Use(logger.Middleware(log))
 	//routes v1
 	customerHandler.Route(app)
 	dmsHandler.Route(app)
 	//docs
 	app.Get("/docs/*", fiberSwagger.WrapHandler)
 	//endpoint not found
-	app.Use(func(ctx *fiber.Ctx) error {
-		return ctx.Status(fiber.StatusNotFound).JSON(dto.Error{
-			Code:    fiber.StatusNotFound,
-			Status:  "NOT FOUND",
-			Errors:  "Page Not Found",
-			TraceID: ctx.Locals("requestid").(string),
-		})
-	})
+	app.Use(exception.NotFound)
+	//workers
+	config.Watch(lc.Context())
+	lc.Go("vehicle sync", vehicleSyncService.Run)
 	//start
-	err := app.Listen(":" + conf.Server.Port)
+	signals := lifecycle.Signals()
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(fmt.Sprintf(":%d", conf.Server.Port))
+	}()
+	lc.OnStop("http server", func(ctx context.Context) error {
+		return app.ShutdownWithContext(ctx)
+	})
+	// runs first: /readyz already fails, give the orchestrator time to notice
+	lc.OnStop("readiness", func(ctx context.Context) error {
+		select {
+		case <-time.After(conf.Server.ShutdownDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	exitCode := 0
+	select {
+	case err := <-listenErr:
+		log.Error("server stopped", slog.String("error", err.Error()))
+		exitCode = 1
+	case sig := <-signals:
+		log.Info("shutting down", slog.String("signal", sig.String()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.Server.ShutdownTimeout)
+	err = lc.Shutdown(ctx)
+	cancel()
 	if err != nil {
-		panic(err)
+		log.Error("shutdown incomplete", slog.String("error", err.Error()))
+		exitCode = 1
 	}
+	os.Exit(exitCode)
 }
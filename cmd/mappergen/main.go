@@ -0,0 +1,462 @@
+// Command mappergen writes the mapping functions between DTOs and entities
+// that the DTOs declare with directives in their doc comments:
+//
+//	//mapper:from entity.Customer
+//	type CustomerResponse struct { ... }
+//
+// generates CustomerResponseFromCustomer(entity.Customer) CustomerResponse and
+// a slice variant; //mapper:to generates the opposite direction. Fields match
+// by name. The mapper tag renames (mapper:"Other"), skips (mapper:"-") or
+// formats a time as a date (mapper:",date"); times otherwise become RFC 3339
+// strings in a location the generated function then takes as its last
+// parameter, the request timezone at the call sites. A DTO field nothing maps
+// to fails generation, and the generated file stops compiling when a mapped
+// struct changes until it is regenerated.
+//
+//	//go:generate go run scylla/cmd/mappergen -source ../entity
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const directive = "//mapper:"
+
+// qualifiedIdent finds the package names in a type such as map[string]*time.Time.
+var qualifiedIdent = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\.`)
+
+func main() {
+	source := flag.String("source", "../entity", "directory of the package the DTOs map from and to")
+	out := flag.String("out", "mapper_gen.go", "file to write, in the current directory")
+	flag.Parse()
+
+	if err := run(".", *source, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "mappergen:", err)
+		os.Exit(1)
+	}
+}
+
+// structType is a struct declaration with its field types written as they
+// must appear in the generated file.
+type structType struct {
+	name   string
+	fields []field
+}
+
+type field struct {
+	name string
+	typ  string
+	tag  reflect.StructTag
+}
+
+// mapping is one //mapper: directive.
+type mapping struct {
+	to     bool
+	dto    structType
+	entity structType
+	// entityType is the entity as the generated file refers to it.
+	entityType string
+}
+
+type pkg struct {
+	name    string
+	path    string
+	structs map[string]structType
+	// imports maps the package names struct fields use to import paths.
+	imports map[string]string
+	// mappings are the directives found in the package.
+	mappings []directiveAt
+}
+
+type directiveAt struct {
+	pos    token.Position
+	to     bool
+	target string
+	dto    string
+}
+
+func run(dir, sourceDir, out string) error {
+	code, err := generate(dir, sourceDir, out)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, out), code, 0o644)
+}
+
+// generate returns what run writes to out, without reading the current out.
+func generate(dir, sourceDir, out string) ([]byte, error) {
+	fset := token.NewFileSet()
+	local, err := load(fset, dir, false, out)
+	if err != nil {
+		return nil, err
+	}
+	source, err := load(fset, sourceDir, true, "")
+	if err != nil {
+		return nil, err
+	}
+	if source.path, err = importPath(sourceDir); err != nil {
+		return nil, err
+	}
+
+	var mappings []mapping
+	var problems []string
+	for _, d := range local.mappings {
+		name, ok := strings.CutPrefix(d.target, source.name+".")
+		target, found := source.structs[name]
+		if !ok || !found {
+			problems = append(problems, fmt.Sprintf("%s: %s is not a struct of package %s", d.pos, d.target, source.name))
+			continue
+		}
+		mappings = append(mappings, mapping{to: d.to, dto: local.structs[d.dto], entity: target, entityType: d.target})
+	}
+	if len(mappings) == 0 && len(problems) == 0 {
+		return nil, fmt.Errorf("no %sfrom or %sto directives in %s", directive, directive, dir)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return funcName(mappings[i]) < funcName(mappings[j])
+	})
+
+	g := generator{imports: map[string]string{source.name: source.path}}
+	for _, m := range mappings {
+		if err := g.mapper(m); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	g.shapes(mappings, local, source)
+
+	return g.file(local.name)
+}
+
+// load parses the structs of the package in dir. Exported type names are
+// qualified with the package name when qualify is set, as the generated file
+// lives in another package.
+func load(fset *token.FileSet, dir string, qualify bool, skip string) (*pkg, error) {
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != skip
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%s must hold exactly one package", dir)
+	}
+
+	var p *pkg
+	for name, parsed := range pkgs {
+		p = &pkg{name: name, structs: map[string]structType{}, imports: map[string]string{}}
+		qualifier := ""
+		if qualify {
+			qualifier = name
+		}
+		for _, file := range parsed.Files {
+			imports := fileImports(file)
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					spec := spec.(*ast.TypeSpec)
+					st, ok := spec.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					s := structType{name: spec.Name.Name}
+					for _, f := range st.Fields.List {
+						typ := typeString(f.Type, qualifier, imports, p.imports)
+						var tag reflect.StructTag
+						if f.Tag != nil {
+							raw, _ := strconv.Unquote(f.Tag.Value)
+							tag = reflect.StructTag(raw)
+						}
+						if len(f.Names) == 0 {
+							s.fields = append(s.fields, field{typ: typ, tag: tag})
+						}
+						for _, n := range f.Names {
+							s.fields = append(s.fields, field{name: n.Name, typ: typ, tag: tag})
+						}
+					}
+					p.structs[s.name] = s
+
+					doc := spec.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					if doc == nil {
+						continue
+					}
+					for _, c := range doc.List {
+						rest, ok := strings.CutPrefix(c.Text, directive)
+						if !ok {
+							continue
+						}
+						verb, target, _ := strings.Cut(rest, " ")
+						if verb != "from" && verb != "to" {
+							return nil, fmt.Errorf("%s: unknown directive %s%s, want from or to", fset.Position(c.Pos()), directive, verb)
+						}
+						p.mappings = append(p.mappings, directiveAt{
+							pos:    fset.Position(c.Pos()),
+							to:     verb == "to",
+							target: strings.TrimSpace(target),
+							dto:    s.name,
+						})
+					}
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+func fileImports(file *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+// typeString writes expr qualifying exported identifiers with qualifier, and
+// records the imports it refers to in used.
+func typeString(expr ast.Expr, qualifier string, imports, used map[string]string) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if qualifier != "" && ast.IsExported(t.Name) {
+			return qualifier + "." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		name := t.X.(*ast.Ident).Name
+		used[name] = imports[name]
+		return name + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + typeString(t.X, qualifier, imports, used)
+	case *ast.ArrayType:
+		length := ""
+		if t.Len != nil {
+			length = t.Len.(*ast.BasicLit).Value
+		}
+		return "[" + length + "]" + typeString(t.Elt, qualifier, imports, used)
+	case *ast.MapType:
+		return "map[" + typeString(t.Key, qualifier, imports, used) + "]" + typeString(t.Value, qualifier, imports, used)
+	case *ast.InterfaceType:
+		return "interface{}"
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+type generator struct {
+	body    bytes.Buffer
+	imports map[string]string
+}
+
+func funcName(m mapping) string {
+	if m.to {
+		return m.entity.name + "From" + m.dto.name
+	}
+	return m.dto.name + "From" + m.entity.name
+}
+
+// mapper writes the functions of one directive.
+func (g *generator) mapper(m mapping) error {
+	srcName, dstName := m.entity.name, m.dto.name
+	srcType, dstType := m.entityType, m.dto.name
+	if m.to {
+		srcName, dstName = dstName, srcName
+		srcType, dstType = dstType, srcType
+	}
+
+	var assigns, guarded []string
+	var problems []string
+	withLocation := false
+	for _, f := range m.dto.fields {
+		if f.name == "" || !ast.IsExported(f.name) {
+			problems = append(problems, fmt.Sprintf("%s: embedded and unexported fields are not supported", m.dto.name))
+			continue
+		}
+		name, option, _ := strings.Cut(f.tag.Get("mapper"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.name
+		}
+		other, ok := lookup(m.entity, name)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s: %s has no field %s", m.dto.name, f.name, m.entity.name, name))
+			continue
+		}
+
+		from, to := other, f
+		if m.to {
+			from, to = f, other
+		}
+		assign, guard, located, err := g.convert(from, to, option == "date")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s.%s: %v", m.dto.name, f.name, err))
+			continue
+		}
+		withLocation = withLocation || located
+		if guard != "" {
+			guarded = append(guarded, fmt.Sprintf("if %s {\ndst.%s = %s\n}", guard, to.name, assign))
+		} else {
+			assigns = append(assigns, fmt.Sprintf("%s: %s,", to.name, assign))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n\t"))
+	}
+
+	name := funcName(m)
+	params, args := "", ""
+	if withLocation {
+		params, args = ", loc *time.Location", ", loc"
+	}
+	fmt.Fprintf(&g.body, "// %s maps %s to %s.\n", name, srcType, dstType)
+	if withLocation {
+		fmt.Fprintf(&g.body, "// Times are rendered in loc.\n")
+	}
+	fmt.Fprintf(&g.body, "func %s(src %s%s) %s {\n", name, srcType, params, dstType)
+	fmt.Fprintf(&g.body, "dst := %s{\n%s\n}\n", dstType, strings.Join(assigns, "\n"))
+	for _, guard := range guarded {
+		fmt.Fprintf(&g.body, "%s\n", guard)
+	}
+	fmt.Fprintf(&g.body, "return dst\n}\n\n")
+
+	plural := dstName + "sFrom" + srcName + "s"
+	fmt.Fprintf(&g.body, "// %s maps each element with %s.\n", plural, name)
+	fmt.Fprintf(&g.body, "func %s(src []%s%s) []%s {\n", plural, srcType, params, dstType)
+	fmt.Fprintf(&g.body, "dst := make([]%s, len(src))\nfor i := range src {\ndst[i] = %s(src[i]%s)\n}\nreturn dst\n}\n\n", dstType, name, args)
+	return nil
+}
+
+func lookup(s structType, name string) (field, bool) {
+	for _, f := range s.fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// convert returns the expression assigning from to to, the condition it must
+// be guarded by when from may be nil, and whether it renders a time in loc.
+// Dates are calendar days and are formatted as they are.
+func (g *generator) convert(from, to field, date bool) (assign, guard string, located bool, err error) {
+	format := ".In(loc).Format(time.RFC3339)"
+	if date {
+		format = ".Format(time.DateOnly)"
+	}
+	value := "src." + from.name
+	switch {
+	case from.typ == to.typ && !date:
+		return value, "", false, nil
+	case from.typ == "time.Time" && to.typ == "string":
+		g.imports["time"] = "time"
+		return value + format, "", !date, nil
+	case from.typ == "*time.Time" && to.typ == "string":
+		g.imports["time"] = "time"
+		return value + format, value + " != nil", !date, nil
+	}
+	return "", "", false, fmt.Errorf("cannot map %s %s to %s %s", from.name, from.typ, to.name, to.typ)
+}
+
+// shapes pins every mapped struct to the fields it had when generated.
+func (g *generator) shapes(mappings []mapping, local, source *pkg) {
+	seen := map[string]bool{}
+	var lines []string
+	add := func(s structType, qualifier string, imports map[string]string) {
+		typ := s.name
+		if qualifier != "" {
+			typ = qualifier + "." + s.name
+		}
+		if seen[typ] {
+			return
+		}
+		seen[typ] = true
+		var fields []string
+		for _, f := range s.fields {
+			fields = append(fields, strings.TrimSpace(f.name+" "+f.typ))
+			for _, match := range qualifiedIdent.FindAllStringSubmatch(f.typ, -1) {
+				if path := imports[match[1]]; path != "" {
+					g.imports[match[1]] = path
+				}
+			}
+		}
+		lines = append(lines, fmt.Sprintf("_ = struct {\n%s\n}(%s{})", strings.Join(fields, "\n"), typ))
+	}
+	for _, m := range mappings {
+		add(m.dto, "", local.imports)
+		add(m.entity, source.name, source.imports)
+	}
+	g.body.WriteString("// The mapped structs as they were when the mappers above were generated;\n")
+	g.body.WriteString("// changing one fails to compile here until go generate runs again.\n")
+	g.body.WriteString("var (\n" + strings.Join(lines, "\n") + "\n)\n")
+}
+
+func (g *generator) file(name string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by mappergen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", name)
+	paths := make([]string, 0, len(g.imports))
+	for _, path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "%q\n", path)
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(g.body.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+// importPath resolves dir to its import path through the enclosing go.mod.
+func importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		file, err := os.Open(filepath.Join(root, "go.mod"))
+		if err == nil {
+			defer file.Close()
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				if module, ok := strings.CutPrefix(scanner.Text(), "module "); ok {
+					rel, err := filepath.Rel(root, abs)
+					if err != nil {
+						return "", err
+					}
+					return strings.TrimSpace(module) + "/" + filepath.ToSlash(rel), nil
+				}
+			}
+			return "", fmt.Errorf("%s has no module line", file.Name())
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("no go.mod above %s", dir)
+		}
+	}
+}
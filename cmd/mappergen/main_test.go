@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestMapperGenUpToDate regenerates dto/mapper_gen.go and fails when it
+// differs from the committed file, i.e. when make generate was not run after
+// a mapped DTO or entity changed.
+func TestMapperGenUpToDate(t *testing.T) {
+	want, err := generate("../../dto", "../../entity", "mapper_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../dto/mapper_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("dto/mapper_gen.go is out of date, run make generate")
+	}
+}
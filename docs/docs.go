@@ -58,21 +58,61 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "start_date",
+                        "example": "2024-01-01",
+                        "description": "created_at from, a date or an RFC 3339 time",
                         "name": "start_date",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "end_date",
+                        "example": "2024-01-31",
+                        "description": "created_at until, a date includes its whole day",
                         "name": "end_date",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "sort",
+                        "example": "created_at:desc,username",
+                        "description": "field[:asc|desc][:nulls_first|nulls_last], comma separated; fields: id, username, email, phone, address, created_at",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter[field][op]=value, op defaults to eq; ops: eq, ne, in (comma separated), like, prefix, gte, lte, null (true/false); fields: id, username, email, phone, address, created_at",
+                        "name": "filter[field][op]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return, e.g. id,username; default all",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated relations to embed: vehicles",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor or prev_cursor of a previous page; sort must stay the same and only use id, created_at",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count total_data and total_page, default true",
+                        "name": "include_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Asia/Jakarta",
+                        "description": "IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone",
+                        "name": "X-Timezone",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -332,13 +372,13 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "start_date",
+                        "description": "created_at from, a date or an RFC 3339 time",
                         "name": "start_date",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "end_date",
+                        "description": "created_at until, a date includes its whole day",
                         "name": "end_date",
                         "in": "query"
                     },
@@ -353,6 +393,19 @@ const docTemplate = `{
                         "description": "email",
                         "name": "email",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "same filters as GET /customers",
+                        "name": "filter[field][op]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Asia/Jakarta",
+                        "description": "IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone",
+                        "name": "X-Timezone",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -457,6 +510,76 @@ const docTemplate = `{
                 }
             }
         },
+        "/customers/search": {
+            "get": {
+                "description": "Search username, email, phone and address by words, word prefixes, typos and similar sounding names, most relevant first. Highlights are HTML escaped with matches wrapped in \u003cmark\u003e.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Search customers.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "jhon",
+                        "description": "search text, 2 to 100 characters",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1 to 100, default 20",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Asia/Jakarta",
+                        "description": "IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone",
+                        "name": "X-Timezone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.CustomerSearchResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadRequest"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
         "/customers/{customerId}": {
             "get": {
                 "description": "get customer by id.",
@@ -474,6 +597,13 @@ const docTemplate = `{
                         "name": "customerId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "Asia/Jakarta",
+                        "description": "IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone",
+                        "name": "X-Timezone",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -582,33 +712,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/vehicles": {
+        "/customers/{customerId}/vehicles": {
             "get": {
-                "description": "Get All vehicles.",
+                "description": "Get the vehicles assigned to a customer.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vehicle"
+                    "customers"
                 ],
-                "summary": "Get All vehicles.",
+                "summary": "Get customer vehicles.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
+                        "description": "customer_id",
+                        "name": "customerId",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "is_active",
-                        "name": "is_active",
+                        "description": "true/false, only assignments effective today",
+                        "name": "active",
                         "in": "query"
                     }
                 ],
@@ -618,7 +743,7 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/dto.Response"
+                                    "$ref": "#/definitions/dto.JsonSuccess"
                                 },
                                 {
                                     "type": "object",
@@ -626,76 +751,732 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/dto.VehicleResponse"
+                                                "$ref": "#/definitions/dto.CustomerVehicleResponse"
                                             }
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadRequest"
+                        }
+                    },
+                    "404": {
+                        "description": "Data not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonInternalServerError"
+                        }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "dto.CreateCustomerBatchRequest": {
-            "type": "object",
-            "required": [
-                "customers"
-            ],
-            "properties": {
-                "customers": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.CreateCustomerRequest"
+            },
+            "post": {
+                "description": "Assign a DMS vehicle to a customer for an effective date range.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Assign vehicle to customer",
+                "parameters": [
+                    {
+                        "description": "assign vehicle",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AssignVehicleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "customer_id",
+                        "name": "customerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DMS customer id",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.JsonCreated"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.CustomerVehicleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant not resolved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonUnauthorized"
+                        }
+                    },
+                    "404": {
+                        "description": "Data not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Overlapping assignment",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonConflict"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonInternalServerError"
+                        }
                     }
                 }
             }
         },
-        "dto.CreateCustomerRequest": {
-            "type": "object",
-            "required": [
-                "address",
-                "email",
-                "phone",
-                "username"
-            ],
-            "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "phone": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
-                }
-            }
-        },
-        "dto.CustomerResponse": {
-            "type": "object",
-            "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "phone": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+        "/customers/{customerId}/vehicles/{vehicleId}": {
+            "delete": {
+                "description": "End the assignment of a vehicle to a customer, today unless effective_to is given.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Unassign vehicle from customer",
+                "parameters": [
+                    {
+                        "description": "unassign vehicle",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UnassignVehicleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "customer_id",
+                        "name": "customerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "vehicle_id",
+                        "name": "vehicleId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.JsonSuccess"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadRequest"
+                        }
+                    },
+                    "404": {
+                        "description": "Data not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is up. It does not check dependencies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe.",
+                "responses": {
+                    "200": {
+                        "description": "Alive",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Checks every registered dependency and reports the status and latency of each. Fails while the server is shutting down.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe.",
+                "responses": {
+                    "200": {
+                        "description": "Ready",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles": {
+            "get": {
+                "description": "Get All vehicles.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicle"
+                ],
+                "summary": "Get All vehicles.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search keyword",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "is_active",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "vehicle_type_id",
+                        "name": "vehicle_type_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "driver_id",
+                        "name": "driver_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "DMS customer id",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.VehicleResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant not resolved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonUnauthorized"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonInternalServerError"
+                        }
+                    },
+                    "502": {
+                        "description": "DMS failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadGateway"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/sync": {
+            "post": {
+                "description": "Mirror the tenant vehicles from DMS into the local vehicles table.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicle"
+                ],
+                "summary": "Sync vehicles from DMS.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "DMS customer id",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Asia/Jakarta",
+                        "description": "IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone",
+                        "name": "X-Timezone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.JsonSuccess"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.VehicleSyncRunResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant not resolved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonUnauthorized"
+                        }
+                    },
+                    "409": {
+                        "description": "Sync already running",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonConflict"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonInternalServerError"
+                        }
+                    },
+                    "502": {
+                        "description": "DMS failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadGateway"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}": {
+            "get": {
+                "description": "Get vehicle by id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicle"
+                ],
+                "summary": "Get vehicle by id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "vehicle_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DMS customer id",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Asia/Jakarta",
+                        "description": "IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone",
+                        "name": "X-Timezone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.JsonSuccess"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.VehicleDetailResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant not resolved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonUnauthorized"
+                        }
+                    },
+                    "404": {
+                        "description": "Data not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonInternalServerError"
+                        }
+                    },
+                    "502": {
+                        "description": "DMS failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadGateway"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/driver": {
+            "get": {
+                "description": "Get the driver currently assigned to a vehicle.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vehicle"
+                ],
+                "summary": "Get driver of a vehicle.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "vehicle_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DMS customer id",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Asia/Jakarta",
+                        "description": "IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone",
+                        "name": "X-Timezone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.JsonSuccess"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.DriverResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant not resolved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonUnauthorized"
+                        }
+                    },
+                    "404": {
+                        "description": "Data not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonInternalServerError"
+                        }
+                    },
+                    "502": {
+                        "description": "DMS failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JsonBadGateway"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AssignVehicleRequest": {
+            "type": "object",
+            "required": [
+                "effective_from",
+                "vehicle_id"
+            ],
+            "properties": {
+                "effective_from": {
+                    "type": "string",
+                    "example": "2024-07-01"
+                },
+                "effective_to": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreateCustomerBatchRequest": {
+            "type": "object",
+            "required": [
+                "customers"
+            ],
+            "properties": {
+                "customers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CreateCustomerRequest"
+                    }
+                }
+            }
+        },
+        "dto.CreateCustomerRequest": {
+            "type": "object",
+            "required": [
+                "address",
+                "email",
+                "phone",
+                "username"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CustomerResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "vehicles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CustomerVehicleResponse"
+                    }
+                }
+            }
+        },
+        "dto.CustomerSearchResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "highlights": {
+                    "description": "Highlights holds the fields whose words matched, HTML escaped with the\nmatches wrapped in \u003cmark\u003e. Fuzzy-only matches are not highlighted.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.87
+                },
+                "username": {
+                    "type": "string"
+                },
+                "vehicles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CustomerVehicleResponse"
+                    }
+                }
+            }
+        },
+        "dto.CustomerVehicleResponse": {
+            "type": "object",
+            "properties": {
+                "cust_id": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "type": "string"
+                },
+                "effective_to": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "vehicle_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -713,6 +1494,78 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.DriverResponse": {
+            "type": "object",
+            "properties": {
+                "driver_id": {
+                    "type": "integer"
+                },
+                "driver_name": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "integer"
+                },
+                "license_expired_at": {
+                    "type": "string"
+                },
+                "license_no": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HealthCheckResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/dto.HealthCheckResponse"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.JsonBadGateway": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 502
+                },
+                "errors": {
+                    "type": "string",
+                    "example": "dms responded with status 503"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "BAD GATEWAY"
+                },
+                "trace_id": {
+                    "type": "string",
+                    "example": "dedc5250-5c20-48c9-9383-fac3ccff2679"
+                }
+            }
+        },
         "dto.JsonBadRequest": {
             "type": "object",
             "properties": {
@@ -740,6 +1593,27 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.JsonConflict": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 409
+                },
+                "errors": {
+                    "type": "string",
+                    "example": "resource is busy or already exists"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "CONFLICT"
+                },
+                "trace_id": {
+                    "type": "string",
+                    "example": "dedc5250-5c20-48c9-9383-fac3ccff2679"
+                }
+            }
+        },
         "dto.JsonCreated": {
             "type": "object",
             "properties": {
@@ -826,15 +1700,42 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.JsonUnauthorized": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 401
+                },
+                "errors": {
+                    "type": "string",
+                    "example": "tenant could not be resolved"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "UNAUTHORIZED"
+                },
+                "trace_id": {
+                    "type": "string",
+                    "example": "dedc5250-5c20-48c9-9383-fac3ccff2679"
+                }
+            }
+        },
         "dto.Meta": {
             "type": "object",
             "properties": {
                 "limit": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "page": {
                     "type": "integer"
                 },
+                "prev_cursor": {
+                    "type": "string"
+                },
                 "total_data": {
                     "type": "integer"
                 },
@@ -864,6 +1765,15 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UnassignVehicleRequest": {
+            "type": "object",
+            "properties": {
+                "effective_to": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                }
+            }
+        },
         "dto.UpdateCustomerRequest": {
             "type": "object",
             "required": [
@@ -891,6 +1801,62 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.VehicleDetailResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "driver_id": {
+                    "type": "integer"
+                },
+                "driver_name": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "number"
+                },
+                "helper_id": {
+                    "type": "integer"
+                },
+                "helper_name": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "integer"
+                },
+                "length": {
+                    "type": "number"
+                },
+                "max_weight": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "vehicle_desc": {
+                    "type": "string"
+                },
+                "vehicle_id": {
+                    "type": "integer"
+                },
+                "vehicle_no": {
+                    "type": "string"
+                },
+                "vehicle_type_id": {
+                    "type": "integer"
+                },
+                "vehicle_type_name": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "number"
+                },
+                "width": {
+                    "type": "number"
+                }
+            }
+        },
         "dto.VehicleResponse": {
             "type": "object",
             "properties": {
@@ -931,6 +1897,44 @@ const docTemplate = `{
                     "type": "number"
                 }
             }
+        },
+        "dto.VehicleSyncRunResponse": {
+            "type": "object",
+            "properties": {
+                "cust_id": {
+                    "type": "string"
+                },
+                "deactivated": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fetched": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "pages": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trigger": {
+                    "type": "string"
+                },
+                "upserted": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -2,19 +2,37 @@ package dto
 
 import "mime/multipart"
 
+//mapper:from entity.Customer
 type CustomerResponse struct {
-	ID        int    `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	Phone     string `json:"phone"`
-	Address   string `json:"address"`
-	CreatedAt string `json:"created_at"`
+	ID        int                       `json:"id"`
+	Username  string                    `json:"username"`
+	Email     string                    `json:"email"`
+	Phone     string                    `json:"phone"`
+	Address   string                    `json:"address"`
+	CreatedAt string                    `json:"created_at"`
+	Vehicles  []CustomerVehicleResponse `json:"vehicles,omitempty" gorm:"-" mapper:"-"`
+}
+
+// CustomerSearchQuery is the query of GET /customers/search.
+type CustomerSearchQuery struct {
+	Q     string `query:"q" json:"q" validate:"required,min=2,max=100" example:"jhon"`
+	Limit int    `query:"limit" json:"limit" validate:"omitempty,min=1,max=100" example:"20"`
+}
+
+// CustomerSearchResponse is a customer found by search, most relevant first.
+type CustomerSearchResponse struct {
+	CustomerResponse
+	Rank float64 `json:"rank" example:"0.87"`
+	// Highlights holds the fields whose words matched, HTML escaped with the
+	// matches wrapped in <mark>. Fuzzy-only matches are not highlighted.
+	Highlights map[string]string `json:"highlights,omitempty"`
 }
 
 type CreateCustomerBatchRequest struct {
 	Customers []CreateCustomerRequest `json:"customers" validate:"required,dive"`
 }
 
+//mapper:to entity.Customer
 type CreateCustomerRequest struct {
 	Username string `json:"username" validate:"required"`
 	Email    string `json:"email" validate:"required,unique=customers;email"`
@@ -42,13 +60,27 @@ type CustomerParams struct {
 	CustomerId int `params:"customerId" validate:"required"`
 }
 
+// CustomerQueryFilter lists customers. StartDate and EndDate bound created_at
+// when both are set; dates cover their whole day in the request timezone, so
+// an EndDate of 2024-01-31 includes that day.
 type CustomerQueryFilter struct {
 	All       bool   `query:"all" example:"true"`
 	Limit     int    `query:"limit"`
 	Page      int    `query:"page"`
-	StartDate string `query:"start_date"`
-	EndDate   string `query:"end_date"`
+	StartDate string `query:"start_date" json:"start_date" validate:"omitempty,dateTime" example:"2024-01-01"`
+	EndDate   string `query:"end_date" json:"end_date" validate:"omitempty,dateTime,dateGteField=StartDate" example:"2024-01-31"`
 	Username  string `query:"username"`
 	Email     string `query:"email"`
 	Sort      string `query:"sort"`
+	// Cursor continues from the next_cursor or prev_cursor of a previous page
+	// instead of using Page.
+	Cursor       string `query:"cursor"`
+	IncludeTotal *bool  `query:"include_total" example:"false"`
+	// Fields limits the response to a comma separated list of fields.
+	Fields string `query:"fields" example:"id,username"`
+	// Include embeds comma separated relations, loaded in one query each.
+	Include string `query:"include" example:"vehicles"`
+	// Filter holds the raw filter[field][op] parameters, which the query
+	// parser cannot bind; see query.FilterParams.
+	Filter map[string]string `query:"-" swaggerignore:"true"`
 }
@@ -0,0 +1,32 @@
+package dto
+
+//mapper:from entity.CustomerVehicle
+type CustomerVehicleResponse struct {
+	ID            int    `json:"id"`
+	CustId        string `json:"cust_id"`
+	VehicleID     int64  `json:"vehicle_id"`
+	EffectiveFrom string `json:"effective_from" mapper:",date"`
+	EffectiveTo   string `json:"effective_to,omitempty" mapper:",date"`
+}
+
+type AssignVehicleRequest struct {
+	CustomerID    int    `json:"-"`
+	VehicleID     int64  `json:"vehicle_id" validate:"required"`
+	EffectiveFrom string `json:"effective_from" validate:"required,datetime=2006-01-02" example:"2024-07-01"`
+	EffectiveTo   string `json:"effective_to" validate:"omitempty,datetime=2006-01-02,dateGteField=EffectiveFrom" example:"2024-12-31"`
+}
+
+type UnassignVehicleRequest struct {
+	CustomerID  int    `json:"-"`
+	VehicleID   int64  `json:"-"`
+	EffectiveTo string `json:"effective_to" validate:"omitempty,datetime=2006-01-02" example:"2024-12-31"`
+}
+
+type CustomerVehicleParams struct {
+	CustomerId int   `params:"customerId" validate:"required"`
+	VehicleId  int64 `params:"vehicleId" validate:"required"`
+}
+
+type CustomerVehicleQueryFilter struct {
+	Active bool `query:"active" example:"true"`
+}
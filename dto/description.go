@@ -36,3 +36,24 @@ type JsonNotFound struct {
 	Errors  string `json:"errors,omitempty" example:"record not found"`
 	TraceID string `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
 }
+
+type JsonUnauthorized struct {
+	Code    int    `json:"code" example:"401"`
+	Status  string `json:"status" example:"UNAUTHORIZED"`
+	Errors  string `json:"errors,omitempty" example:"tenant could not be resolved"`
+	TraceID string `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
+}
+
+type JsonBadGateway struct {
+	Code    int    `json:"code" example:"502"`
+	Status  string `json:"status" example:"BAD GATEWAY"`
+	Errors  string `json:"errors,omitempty" example:"dms responded with status 503"`
+	TraceID string `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
+}
+
+type JsonConflict struct {
+	Code    int    `json:"code" example:"409"`
+	Status  string `json:"status" example:"CONFLICT"`
+	Errors  string `json:"errors,omitempty" example:"resource is busy or already exists"`
+	TraceID string `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
+}
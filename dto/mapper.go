@@ -0,0 +1,6 @@
+package dto
+
+// Types marked //mapper:from or //mapper:to get entity mapping functions in
+// mapper_gen.go; see cmd/mappergen.
+//
+//go:generate go run scylla/cmd/mappergen -source ../entity
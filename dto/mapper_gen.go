@@ -0,0 +1,176 @@
+// Code generated by mappergen. DO NOT EDIT.
+
+package dto
+
+import (
+	"scylla/entity"
+	"time"
+)
+
+// CustomerFromCreateCustomerRequest maps CreateCustomerRequest to entity.Customer.
+func CustomerFromCreateCustomerRequest(src CreateCustomerRequest) entity.Customer {
+	dst := entity.Customer{
+		Username: src.Username,
+		Email:    src.Email,
+		Phone:    src.Phone,
+		Address:  src.Address,
+	}
+	return dst
+}
+
+// CustomersFromCreateCustomerRequests maps each element with CustomerFromCreateCustomerRequest.
+func CustomersFromCreateCustomerRequests(src []CreateCustomerRequest) []entity.Customer {
+	dst := make([]entity.Customer, len(src))
+	for i := range src {
+		dst[i] = CustomerFromCreateCustomerRequest(src[i])
+	}
+	return dst
+}
+
+// CustomerResponseFromCustomer maps entity.Customer to CustomerResponse.
+// Times are rendered in loc.
+func CustomerResponseFromCustomer(src entity.Customer, loc *time.Location) CustomerResponse {
+	dst := CustomerResponse{
+		ID:        src.ID,
+		Username:  src.Username,
+		Email:     src.Email,
+		Phone:     src.Phone,
+		Address:   src.Address,
+		CreatedAt: src.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+	return dst
+}
+
+// CustomerResponsesFromCustomers maps each element with CustomerResponseFromCustomer.
+func CustomerResponsesFromCustomers(src []entity.Customer, loc *time.Location) []CustomerResponse {
+	dst := make([]CustomerResponse, len(src))
+	for i := range src {
+		dst[i] = CustomerResponseFromCustomer(src[i], loc)
+	}
+	return dst
+}
+
+// CustomerVehicleResponseFromCustomerVehicle maps entity.CustomerVehicle to CustomerVehicleResponse.
+func CustomerVehicleResponseFromCustomerVehicle(src entity.CustomerVehicle) CustomerVehicleResponse {
+	dst := CustomerVehicleResponse{
+		ID:            src.ID,
+		CustId:        src.CustId,
+		VehicleID:     src.VehicleID,
+		EffectiveFrom: src.EffectiveFrom.Format(time.DateOnly),
+	}
+	if src.EffectiveTo != nil {
+		dst.EffectiveTo = src.EffectiveTo.Format(time.DateOnly)
+	}
+	return dst
+}
+
+// CustomerVehicleResponsesFromCustomerVehicles maps each element with CustomerVehicleResponseFromCustomerVehicle.
+func CustomerVehicleResponsesFromCustomerVehicles(src []entity.CustomerVehicle) []CustomerVehicleResponse {
+	dst := make([]CustomerVehicleResponse, len(src))
+	for i := range src {
+		dst[i] = CustomerVehicleResponseFromCustomerVehicle(src[i])
+	}
+	return dst
+}
+
+// VehicleSyncRunResponseFromVehicleSyncRun maps entity.VehicleSyncRun to VehicleSyncRunResponse.
+// Times are rendered in loc.
+func VehicleSyncRunResponseFromVehicleSyncRun(src entity.VehicleSyncRun, loc *time.Location) VehicleSyncRunResponse {
+	dst := VehicleSyncRunResponse{
+		ID:          src.ID,
+		CustId:      src.CustId,
+		Trigger:     src.Trigger,
+		Status:      src.Status,
+		Pages:       src.Pages,
+		Fetched:     src.Fetched,
+		Upserted:    src.Upserted,
+		Deactivated: src.Deactivated,
+		Error:       src.Error,
+		StartedAt:   src.StartedAt.In(loc).Format(time.RFC3339),
+	}
+	if src.FinishedAt != nil {
+		dst.FinishedAt = src.FinishedAt.In(loc).Format(time.RFC3339)
+	}
+	return dst
+}
+
+// VehicleSyncRunResponsesFromVehicleSyncRuns maps each element with VehicleSyncRunResponseFromVehicleSyncRun.
+func VehicleSyncRunResponsesFromVehicleSyncRuns(src []entity.VehicleSyncRun, loc *time.Location) []VehicleSyncRunResponse {
+	dst := make([]VehicleSyncRunResponse, len(src))
+	for i := range src {
+		dst[i] = VehicleSyncRunResponseFromVehicleSyncRun(src[i], loc)
+	}
+	return dst
+}
+
+// The mapped structs as they were when the mappers above were generated;
+// changing one fails to compile here until go generate runs again.
+var (
+	_ = struct {
+		Username string
+		Email    string
+		Phone    string
+		Address  string
+	}(CreateCustomerRequest{})
+	_ = struct {
+		ID        int
+		Username  string
+		Email     string
+		Phone     string
+		Address   string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}(entity.Customer{})
+	_ = struct {
+		ID        int
+		Username  string
+		Email     string
+		Phone     string
+		Address   string
+		CreatedAt string
+		Vehicles  []CustomerVehicleResponse
+	}(CustomerResponse{})
+	_ = struct {
+		ID            int
+		CustId        string
+		VehicleID     int64
+		EffectiveFrom string
+		EffectiveTo   string
+	}(CustomerVehicleResponse{})
+	_ = struct {
+		ID            int
+		CustomerID    int
+		CustId        string
+		VehicleID     int64
+		EffectiveFrom time.Time
+		EffectiveTo   *time.Time
+		CreatedAt     time.Time
+		UpdatedAt     time.Time
+	}(entity.CustomerVehicle{})
+	_ = struct {
+		ID          int
+		CustId      string
+		Trigger     string
+		Status      string
+		Pages       int
+		Fetched     int
+		Upserted    int
+		Deactivated int
+		Error       string
+		StartedAt   string
+		FinishedAt  string
+	}(VehicleSyncRunResponse{})
+	_ = struct {
+		ID          int
+		CustId      string
+		Trigger     string
+		Status      string
+		Pages       int
+		Fetched     int
+		Upserted    int
+		Deactivated int
+		Error       string
+		StartedAt   time.Time
+		FinishedAt  *time.Time
+	}(entity.VehicleSyncRun{})
+)
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"fmt"
+	"scylla/entity"
+	"scylla/pkg/helper"
+	"testing"
+	"time"
+)
+
+// BenchmarkCustomerMapping compares mapping a FindAll page of customers with
+// the generated mapper against the JSON round trip of helper.Automapper it
+// replaced.
+//
+//	go test ./dto -run '^$' -bench CustomerMapping -benchmem
+func BenchmarkCustomerMapping(b *testing.B) {
+	page := make([]entity.Customer, 100)
+	for i := range page {
+		page[i] = entity.Customer{
+			ID:        i + 1,
+			Username:  fmt.Sprintf("customer%d", i+1),
+			Email:     fmt.Sprintf("customer%d@example.com", i+1),
+			Phone:     "+6281234567890",
+			Address:   "Jl. Jend. Sudirman No. 1, Jakarta",
+			CreatedAt: time.Date(2024, 7, 11, 8, 41, 22, 0, time.UTC),
+		}
+	}
+
+	b.Run("automapper", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			response := make([]CustomerResponse, 0, len(page))
+			for _, customer := range page {
+				var res CustomerResponse
+				helper.Automapper(customer, &res)
+				response = append(response, res)
+			}
+		}
+	})
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_ = CustomerResponsesFromCustomers(page, time.UTC)
+		}
+	})
+}
@@ -14,3 +14,36 @@ type VehicleResponse struct {
 	DriverName  string  `json:"driver_name"`
 	HelperName  string  `json:"helper_name"`
 }
+
+type VehicleDetailResponse struct {
+	VehicleResponse
+	VehicleTypeID int64   `json:"vehicle_type_id"`
+	MaxWeight     float64 `json:"max_weight"`
+	IsActive      int     `json:"is_active"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+type DriverResponse struct {
+	DriverID         int64  `json:"driver_id"`
+	DriverName       string `json:"driver_name"`
+	Phone            string `json:"phone"`
+	LicenseNo        string `json:"license_no"`
+	LicenseExpiredAt string `json:"license_expired_at"`
+	IsActive         int    `json:"is_active"`
+}
+
+type VehicleParams struct {
+	VehicleId int64 `params:"id" validate:"required"`
+}
+
+type VehicleQueryFilter struct {
+	Page          int    `query:"page"`
+	Limit         int    `query:"limit"`
+	Query         string `query:"q"`
+	Mode          string `query:"mode"`
+	Sort          string `query:"sort"`
+	IsActive      int    `query:"is_active"`
+	VehicleTypeId int64  `query:"vehicle_type_id"`
+	DriverId      int64  `query:"driver_id"`
+}
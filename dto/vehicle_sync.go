@@ -0,0 +1,16 @@
+package dto
+
+//mapper:from entity.VehicleSyncRun
+type VehicleSyncRunResponse struct {
+	ID          int    `json:"id"`
+	CustId      string `json:"cust_id"`
+	Trigger     string `json:"trigger"`
+	Status      string `json:"status"`
+	Pages       int    `json:"pages"`
+	Fetched     int    `json:"fetched"`
+	Upserted    int    `json:"upserted"`
+	Deactivated int    `json:"deactivated"`
+	Error       string `json:"error,omitempty"`
+	StartedAt   string `json:"started_at"`
+	FinishedAt  string `json:"finished_at,omitempty"`
+}
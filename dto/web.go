@@ -18,6 +18,26 @@ type Error struct {
 	TraceID string      `json:"trace_id"`
 }
 
+// Problem is the RFC 7807 application/problem+json error body, sent instead of
+// Error when the client asks for it in Accept.
+type Problem struct {
+	Type     string       `json:"type" example:"urn:problem:customer-not-found"`
+	Title    string       `json:"title" example:"Customer not found"`
+	Status   int          `json:"status" example:"404"`
+	Detail   string       `json:"detail,omitempty" example:"customer not found"`
+	Instance string       `json:"instance,omitempty" example:"/api/v1/customers/42"`
+	Code     string       `json:"code" example:"CUSTOMER_NOT_FOUND"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	TraceID  string       `json:"trace_id" example:"dedc5250-5c20-48c9-9383-fac3ccff2679"`
+}
+
+// FieldError is one failed rule of a field in Problem.Errors.
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"email is required"`
+}
+
 type Paging struct {
 	TotalRecord int `json:"total_record"`
 	PageCurrent int `json:"page_current"`
@@ -25,11 +45,16 @@ type Paging struct {
 	PageTotal   int `json:"page_total"`
 }
 
+// Meta describes a list page. TotalData and TotalPage are always sent, zero
+// included, unless the client skipped counting; NextCursor and PrevCursor are
+// set on lists that support cursor pagination.
 type Meta struct {
-	Limit     int `json:"limit"`
-	Page      int `json:"page"`
-	TotalData int `json:"total_data"`
-	TotalPage int `json:"total_page"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page"`
+	TotalData  *int   `json:"total_data,omitempty"`
+	TotalPage  *int   `json:"total_page,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 func Paginate(page int, limit int) func(db *gorm.DB) *gorm.DB {
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+type CustomerVehicle struct {
+	ID            int        `json:"id" gorm:"type:int;primary_key"`
+	CustomerID    int        `json:"customer_id"`
+	CustId        string     `json:"cust_id"`
+	VehicleID     int64      `json:"vehicle_id"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (CustomerVehicle) TableName() string {
+	return "customer_vehicles"
+}
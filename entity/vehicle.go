@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+type Vehicle struct {
+	ID              int       `json:"id" gorm:"type:int;primary_key"`
+	CustId          string    `json:"cust_id"`
+	VehicleID       int64     `json:"vehicle_id"`
+	VehicleNo       string    `json:"vehicle_no"`
+	VehicleDesc     string    `json:"vehicle_desc"`
+	VehicleTypeName string    `json:"vehicle_type_name"`
+	Length          float64   `json:"length"`
+	Width           float64   `json:"width"`
+	Height          float64   `json:"height"`
+	Volume          float64   `json:"volume"`
+	DriverID        int64     `json:"driver_id"`
+	HelperID        int64     `json:"helper_id"`
+	DriverName      string    `json:"driver_name"`
+	HelperName      string    `json:"helper_name"`
+	IsActive        bool      `json:"is_active"`
+	SyncedAt        time.Time `json:"synced_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (Vehicle) TableName() string {
+	return "vehicles"
+}
+
+type VehicleSyncRun struct {
+	ID          int        `json:"id" gorm:"type:int;primary_key"`
+	CustId      string     `json:"cust_id"`
+	Trigger     string     `json:"trigger"`
+	Status      string     `json:"status"`
+	Pages       int        `json:"pages"`
+	Fetched     int        `json:"fetched"`
+	Upserted    int        `json:"upserted"`
+	Deactivated int        `json:"deactivated"`
+	Error       string     `json:"error"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+func (VehicleSyncRun) TableName() string {
+	return "vehicle_sync_runs"
+}
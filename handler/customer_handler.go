@@ -8,7 +8,7 @@ import (
 	"path/filepath"
 	"scylla/dto"
 	"scylla/pkg/exception"
-	"scylla/pkg/helper"
+	"scylla/pkg/query"
 	"scylla/pkg/utils"
 	"scylla/service"
 	"time"
@@ -28,6 +28,7 @@ func (handler *CustomerHandler) Route(app *fiber.App) {
 	qParamId := ":customerId"
 	customerRouter := app.Group("/api/v1/customers")
 	customerRouter.Get("", handler.FindAll)
+	customerRouter.Get("/search", handler.Search)
 	customerRouter.Get("/"+qParamId, handler.FindById)
 	customerRouter.Get("/export", handler.Export)
 	customerRouter.Post("/import", handler.Import)
@@ -35,6 +36,9 @@ func (handler *CustomerHandler) Route(app *fiber.App) {
 	customerRouter.Post("/batch", handler.CreateBatch)
 	customerRouter.Patch("/"+qParamId, handler.Update)
 	customerRouter.Delete("/batch", handler.DeleteBatch)
+	customerRouter.Get("/"+qParamId+"/vehicles", handler.FindVehicles)
+	customerRouter.Post("/"+qParamId+"/vehicles", handler.AssignVehicle)
+	customerRouter.Delete("/"+qParamId+"/vehicles/:vehicleId", handler.UnassignVehicle)
 }
 
 // Note            godoc
@@ -54,10 +58,13 @@ func (handler *CustomerHandler) Create(ctx *fiber.Ctx) error {
 	defer cancel()
 
 	request := dto.CreateCustomerRequest{}
-	err := ctx.BodyParser(&request)
-	helper.ErrorPanic(err)
+	if err := ctx.BodyParser(&request); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
 
-	handler.customerService.Create(c, request)
+	if err := handler.customerService.Create(c, request); err != nil {
+		return err
+	}
 
 	webResponse := dto.Response{
 		Code:    fiber.StatusCreated,
@@ -85,10 +92,13 @@ func (handler *CustomerHandler) CreateBatch(ctx *fiber.Ctx) error {
 	defer cancel()
 
 	request := dto.CreateCustomerBatchRequest{}
-	err := ctx.BodyParser(&request)
-	helper.ErrorPanic(err)
+	if err := ctx.BodyParser(&request); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
 
-	handler.customerService.CreateBatch(c, request)
+	if err := handler.customerService.CreateBatch(c, request); err != nil {
+		return err
+	}
 
 	webResponse := dto.Response{
 		Code:    fiber.StatusCreated,
@@ -117,18 +127,21 @@ func (handler *CustomerHandler) Update(ctx *fiber.Ctx) error {
 	defer cancel()
 
 	request := dto.UpdateCustomerRequest{}
-	err := ctx.BodyParser(&request)
-	helper.ErrorPanic(err)
+	if err := ctx.BodyParser(&request); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
 
 	var params dto.CustomerParams
 
 	if err := ctx.ParamsParser(&params); err != nil {
-		panic(exception.NewBadRequestHandler(err.Error()))
+		return exception.NewBadRequestHandler(err.Error())
 	}
 
 	request.ID = params.CustomerId
 
-	handler.customerService.Update(c, request)
+	if err := handler.customerService.Update(c, request); err != nil {
+		return err
+	}
 
 	webResponse := dto.Response{
 		Code:    fiber.StatusOK,
@@ -157,10 +170,13 @@ func (handler *CustomerHandler) DeleteBatch(ctx *fiber.Ctx) error {
 	defer cancel()
 
 	request := dto.DeleteBatchCustomerRequest{}
-	err := ctx.BodyParser(&request)
-	helper.ErrorPanic(err)
+	if err := ctx.BodyParser(&request); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
 
-	handler.customerService.DeleteBatch(c, request)
+	if err := handler.customerService.DeleteBatch(c, request); err != nil {
+		return err
+	}
 
 	webResponse := dto.Response{
 		Code:    fiber.StatusOK,
@@ -176,6 +192,7 @@ func (handler *CustomerHandler) DeleteBatch(ctx *fiber.Ctx) error {
 //
 //	@Summary		get customer by id.
 //	@Param			customerId	path	string	true	"customer_id"
+//	@Param			X-Timezone	header	string	false	"IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone"	example(Asia/Jakarta)
 //	@Description	get customer by id.
 //	@Produce		application/json
 //	@Tags			customers
@@ -191,10 +208,13 @@ func (handler *CustomerHandler) FindById(ctx *fiber.Ctx) error {
 	var params dto.CustomerParams
 
 	if err := ctx.ParamsParser(&params); err != nil {
-		panic(exception.NewBadRequestHandler(err.Error()))
+		return exception.NewBadRequestHandler(err.Error())
 	}
 
-	data := handler.customerService.FindById(c, params)
+	data, err := handler.customerService.FindById(c, params)
+	if err != nil {
+		return err
+	}
 
 	webResponse := dto.Response{
 		Code:   fiber.StatusOK,
@@ -215,9 +235,15 @@ func (handler *CustomerHandler) FindById(ctx *fiber.Ctx) error {
 //	@Param			page		query	string	false	"page"
 //	@Param			username	query	string	false	"username"
 //	@Param			email		query	string	false	"email"
-//	@Param			start_date	query	string	false	"start_date"
-//	@Param			end_date	query	string	false	"end_date"
-//	@Param			sort		query	string	false	"sort"
+//	@Param			start_date	query	string	false	"created_at from, a date or an RFC 3339 time"	example(2024-01-01)
+//	@Param			end_date	query	string	false	"created_at until, a date includes its whole day"	example(2024-01-31)
+//	@Param			sort		query	string	false	"field[:asc|desc][:nulls_first|nulls_last], comma separated; fields: id, username, email, phone, address, created_at"	example(created_at:desc,username)
+//	@Param			filter[field][op]	query	string	false	"filter[field][op]=value, op defaults to eq; ops: eq, ne, in (comma separated), like, prefix, gte, lte, null (true/false); fields: id, username, email, phone, address, created_at"
+//	@Param			fields			query	string	false	"comma separated fields to return, e.g. id,username; default all"
+//	@Param			include			query	string	false	"comma separated relations to embed: vehicles"
+//	@Param			cursor			query	string	false	"next_cursor or prev_cursor of a previous page; sort must stay the same and only use id, created_at"
+//	@Param			include_total	query	bool	false	"count total_data and total_page, default true"
+//	@Param			X-Timezone		header	string	false	"IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone"	example(Asia/Jakarta)
 //	@Tags			customers
 //	@Success		200	{object}	dto.Response{data=[]dto.CustomerResponse{}}	    "Data"
 //	@Failure		400	{object}	dto.JsonBadRequest{}							"Validation error"
@@ -231,10 +257,14 @@ func (handler *CustomerHandler) FindAll(ctx *fiber.Ctx) error {
 	var dataFilter dto.CustomerQueryFilter
 
 	if err := ctx.QueryParser(&dataFilter); err != nil {
-		panic(exception.NewBadRequestHandler(err.Error()))
+		return exception.NewBadRequestHandler(err.Error())
 	}
+	dataFilter.Filter = query.FilterParams(ctx.Queries())
 
-	response, paging := handler.customerService.FindAll(c, dataFilter)
+	response, paging, err := handler.customerService.FindAll(c, dataFilter)
+	if err != nil {
+		return err
+	}
 
 	webResponse := dto.Response{
 		Code:   fiber.StatusOK,
@@ -253,10 +283,12 @@ func (handler *CustomerHandler) FindAll(ctx *fiber.Ctx) error {
 // @Produce		application/json
 // @Tags		customers
 // @Param		all     	query		string	true	"true"
-// @Param		start_date	query		string	false	"start_date"
-// @Param		end_date	query		string	false	"end_date"
+// @Param		start_date	query		string	false	"created_at from, a date or an RFC 3339 time"
+// @Param		end_date	query		string	false	"created_at until, a date includes its whole day"
 // @Param		username	query		string	false	"username"
 // @Param		email		query		string	false	"email"
+// @Param		filter[field][op]	query	string	false	"same filters as GET /customers"
+// @Param		X-Timezone	header	string	false	"IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone"	example(Asia/Jakarta)
 // @Success		200			{object}	dto.JsonSuccess{data=string}    "Data"
 // @Failure		400			{object}	dto.JsonBadRequest{}			"Validation error"
 // @Failure		404			{object}	dto.JsonNotFound{}				"Data not found"
@@ -269,11 +301,14 @@ func (handler *CustomerHandler) Export(ctx *fiber.Ctx) error {
 	var dataFilter dto.CustomerQueryFilter
 
 	if err := ctx.QueryParser(&dataFilter); err != nil {
-		panic(exception.NewBadRequestHandler(err.Error()))
+		return exception.NewBadRequestHandler(err.Error())
 	}
+	dataFilter.Filter = query.FilterParams(ctx.Queries())
 
 	filePath, err := handler.customerService.Export(c, dataFilter)
-	helper.ErrorPanic(err)
+	if err != nil {
+		return err
+	}
 	defer os.Remove(filePath) // Remove the file after the function exits
 
 	fileName := filepath.Base(filePath)
@@ -283,12 +318,51 @@ func (handler *CustomerHandler) Export(ctx *fiber.Ctx) error {
 
 	// Read the Excel file and write to the response body
 	data, err := os.ReadFile(filePath)
-	helper.ErrorPanic(err)
+	if err != nil {
+		return err
+	}
 
 	// Write data to the response body
 	return ctx.Status(fiber.StatusOK).Send(data)
 }
 
+// Note             godoc
+//
+//	@Summary		Search customers.
+//	@Description	Search username, email, phone and address by words, word prefixes, typos and similar sounding names, most relevant first. Highlights are HTML escaped with matches wrapped in <mark>.
+//	@Produce		application/json
+//	@Param			q		query	string	true	"search text, 2 to 100 characters"	example(jhon)
+//	@Param			limit	query	int		false	"1 to 100, default 20"
+//	@Param			X-Timezone	header	string	false	"IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone"	example(Asia/Jakarta)
+//	@Tags			customers
+//	@Success		200	{object}	dto.Response{data=[]dto.CustomerSearchResponse{}}	"Data"
+//	@Failure		400	{object}	dto.JsonBadRequest{}								"Validation error"
+//	@Failure		500	{object}	dto.JsonInternalServerError{}						"Internal server error"
+//	@Router			/customers/search [get]
+func (handler *CustomerHandler) Search(ctx *fiber.Ctx) error {
+	c, cancel := context.WithTimeout(ctx.Context(), 30*time.Second)
+	defer cancel()
+
+	var request dto.CustomerSearchQuery
+
+	if err := ctx.QueryParser(&request); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
+
+	response, err := handler.customerService.Search(c, request)
+	if err != nil {
+		return err
+	}
+
+	webResponse := dto.Response{
+		Code:   fiber.StatusOK,
+		Status: "OK",
+		Data:   response,
+	}
+	utils.ResponseInterceptor(c, &webResponse)
+	return ctx.Status(fiber.StatusOK).JSON(webResponse)
+}
+
 // Note 		    godoc
 //
 // @Summary		Import Excel customer.
@@ -310,18 +384,19 @@ func (handler *CustomerHandler) Import(ctx *fiber.Ctx) error {
 
 	file, err := ctx.FormFile("file")
 	if err != nil {
-		panic(exception.NewBadRequestHandler(err.Error()))
+		return exception.NewBadRequestHandler(err.Error())
 	}
 
 	fileExtension := filepath.Ext(file.Filename)
 	if fileExtension != ".xlsx" && fileExtension != ".xls" {
-		panic(exception.NewBadRequestHandler("Invalid file type. Only .xlsx and .xls are allowed"))
+		return exception.NewBadRequestHandler("Invalid file type. Only .xlsx and .xls are allowed").WithCode(exception.CodeInvalidFileType)
 	}
 
 	request.File = file
 
-	error := handler.customerService.Import(c, *request)
-	helper.ErrorPanic(error)
+	if err := handler.customerService.Import(c, *request); err != nil {
+		return err
+	}
 
 	webResponse := dto.Response{
 		Code:    fiber.StatusOK,
@@ -332,3 +407,142 @@ func (handler *CustomerHandler) Import(ctx *fiber.Ctx) error {
 	utils.ResponseInterceptor(c, &webResponse)
 	return ctx.Status(fiber.StatusOK).JSON(webResponse)
 }
+
+// Note             godoc
+//
+//	@Summary		Assign vehicle to customer
+//	@Description	Assign a DMS vehicle to a customer for an effective date range.
+//	@Param			data		body	dto.AssignVehicleRequest	true	"assign vehicle"
+//	@Param			customerId	path	string						true	"customer_id"
+//	@Param			X-Tenant-ID	header	string						false	"DMS customer id"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		201	{object}	dto.JsonCreated{data=dto.CustomerVehicleResponse{}}	"Data"
+//	@Failure		400	{object}	dto.JsonBadRequest{}								"Validation error"
+//	@Failure		401	{object}	dto.JsonUnauthorized{}								"Tenant not resolved"
+//	@Failure		404	{object}	dto.JsonNotFound{}									"Data not found"
+//	@Failure		409	{object}	dto.JsonConflict{}									"Overlapping assignment"
+//	@Failure		500	{object}	dto.JsonInternalServerError{}						"Internal server error"
+//	@Router			/customers/{customerId}/vehicles [post]
+func (handler *CustomerHandler) AssignVehicle(ctx *fiber.Ctx) error {
+	c, cancel := context.WithTimeout(ctx.Context(), 30*time.Second)
+	defer cancel()
+
+	request := dto.AssignVehicleRequest{}
+	if err := ctx.BodyParser(&request); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
+
+	var params dto.CustomerParams
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
+
+	request.CustomerID = params.CustomerId
+
+	data, err := handler.customerService.AssignVehicle(c, request)
+	if err != nil {
+		return err
+	}
+
+	webResponse := dto.Response{
+		Code:    fiber.StatusCreated,
+		Status:  "Created",
+		Message: "Assign Successful",
+		Data:    data,
+	}
+	utils.ResponseInterceptor(c, &webResponse)
+	return ctx.Status(fiber.StatusCreated).JSON(webResponse)
+}
+
+// Note             godoc
+//
+//	@Summary		Unassign vehicle from customer
+//	@Description	End the assignment of a vehicle to a customer, today unless effective_to is given.
+//	@Param			data		body	dto.UnassignVehicleRequest	false	"unassign vehicle"
+//	@Param			customerId	path	string						true	"customer_id"
+//	@Param			vehicleId	path	string						true	"vehicle_id"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	dto.JsonSuccess{data=nil}		"Data"
+//	@Failure		400	{object}	dto.JsonBadRequest{}			"Validation error"
+//	@Failure		404	{object}	dto.JsonNotFound{}				"Data not found"
+//	@Failure		500	{object}	dto.JsonInternalServerError{}	"Internal server error"
+//	@Router			/customers/{customerId}/vehicles/{vehicleId} [delete]
+func (handler *CustomerHandler) UnassignVehicle(ctx *fiber.Ctx) error {
+	c, cancel := context.WithTimeout(ctx.Context(), 30*time.Second)
+	defer cancel()
+
+	request := dto.UnassignVehicleRequest{}
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&request); err != nil {
+			return exception.NewBadRequestHandler(err.Error())
+		}
+	}
+
+	var params dto.CustomerVehicleParams
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
+
+	request.CustomerID = params.CustomerId
+	request.VehicleID = params.VehicleId
+
+	if err := handler.customerService.UnassignVehicle(c, request); err != nil {
+		return err
+	}
+
+	webResponse := dto.Response{
+		Code:    fiber.StatusOK,
+		Status:  "OK",
+		Message: "Unassign Successful",
+		Data:    nil,
+	}
+	utils.ResponseInterceptor(c, &webResponse)
+	return ctx.Status(fiber.StatusOK).JSON(webResponse)
+}
+
+// Note             godoc
+//
+//	@Summary		Get customer vehicles.
+//	@Description	Get the vehicles assigned to a customer.
+//	@Param			customerId	path	string	true	"customer_id"
+//	@Param			active		query	string	false	"true/false, only assignments effective today"
+//	@Produce		application/json
+//	@Tags			customers
+//	@Success		200	{object}	dto.JsonSuccess{data=[]dto.CustomerVehicleResponse{}}	"Data"
+//	@Failure		400	{object}	dto.JsonBadRequest{}									"Validation error"
+//	@Failure		404	{object}	dto.JsonNotFound{}										"Data not found"
+//	@Failure		500	{object}	dto.JsonInternalServerError{}							"Internal server error"
+//	@Router			/customers/{customerId}/vehicles [get]
+func (handler *CustomerHandler) FindVehicles(ctx *fiber.Ctx) error {
+	c, cancel := context.WithTimeout(ctx.Context(), 30*time.Second)
+	defer cancel()
+
+	var params dto.CustomerParams
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
+
+	var dataFilter dto.CustomerVehicleQueryFilter
+
+	if err := ctx.QueryParser(&dataFilter); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
+
+	data, err := handler.customerService.FindVehicles(c, params, dataFilter)
+	if err != nil {
+		return err
+	}
+
+	webResponse := dto.Response{
+		Code:   fiber.StatusOK,
+		Status: "OK",
+		Data:   data,
+	}
+	utils.ResponseInterceptor(c, &webResponse)
+	return ctx.Status(fiber.StatusOK).JSON(webResponse)
+}
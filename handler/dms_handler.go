@@ -5,24 +5,32 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"scylla/dto"
 	"scylla/pkg/exception"
-	"scylla/pkg/helper"
 	"scylla/pkg/utils"
 	"scylla/service"
 	"time"
 )
 
 type DmsHandler struct {
-	dmsService service.DmsService
+	dmsService         service.DmsService
+	vehicleSyncService service.VehicleSyncService
+	validate           *utils.Validator
 }
 
-func NewDmsHandler(service service.DmsService) *DmsHandler {
+func NewDmsHandler(service service.DmsService, vehicleSyncService service.VehicleSyncService, validate *utils.Validator) *DmsHandler {
 	return &DmsHandler{
-		dmsService: service,
+		dmsService:         service,
+		vehicleSyncService: vehicleSyncService,
+		validate:           validate,
 	}
 }
 
 func (handler *DmsHandler) Route(app *fiber.App) {
-	app.Get("api/v1/vehicles", handler.GetVehicle)
+	qParamId := ":id"
+	vehicleRouter := app.Group("/api/v1/vehicles")
+	vehicleRouter.Get("", handler.GetVehicle)
+	vehicleRouter.Post("/sync", handler.Sync)
+	vehicleRouter.Get("/"+qParamId, handler.GetVehicleById)
+	vehicleRouter.Get("/"+qParamId+"/driver", handler.GetVehicleDriver)
 }
 
 // Note             godoc
@@ -30,24 +38,35 @@ func (handler *DmsHandler) Route(app *fiber.App) {
 //	@Summary		Get All vehicles.
 //	@Description	Get All vehicles.
 //	@Produce		application/json
-//	@Param			limit		query	string	false	"limit"
-//	@Param			page		query	string	false	"page"
-//	@Param			is_active	query	string	false	"is_active"
+//	@Param			limit			query	string	false	"limit"
+//	@Param			page			query	string	false	"page"
+//	@Param			q				query	string	false	"search keyword"
+//	@Param			mode			query	string	false	"search mode"
+//	@Param			sort			query	string	false	"sort"
+//	@Param			is_active		query	string	false	"is_active"
+//	@Param			vehicle_type_id	query	string	false	"vehicle_type_id"
+//	@Param			driver_id		query	string	false	"driver_id"
+//	@Param			X-Tenant-ID		header	string	false	"DMS customer id"
 //	@Tags			vehicle
 //	@Success		200	{object}	dto.Response{data=[]dto.VehicleResponse}	"Data"
+//	@Failure		401	{object}	dto.JsonUnauthorized{}						"Tenant not resolved"
+//	@Failure		500	{object}	dto.JsonInternalServerError{}				"Internal server error"
+//	@Failure		502	{object}	dto.JsonBadGateway{}						"DMS failed"
 //	@Router			/vehicles [get]
 func (handler *DmsHandler) GetVehicle(ctx *fiber.Ctx) error {
 	c, cancel := context.WithTimeout(ctx.Context(), 30*time.Second)
 	defer cancel()
 
-	var dataFilter dto.GeneralQueryFilter
+	var dataFilter dto.VehicleQueryFilter
 
 	if err := ctx.QueryParser(&dataFilter); err != nil {
-		panic(exception.NewBadRequestHandler(err.Error()))
+		return exception.NewBadRequestHandler(err.Error())
 	}
 
 	response, paging, err := handler.dmsService.GetVehicle(c, dataFilter)
-	helper.ErrorPanic(err)
+	if err != nil {
+		return err
+	}
 
 	webResponse := dto.Response{
 		Code:   fiber.StatusOK,
@@ -58,3 +77,122 @@ func (handler *DmsHandler) GetVehicle(ctx *fiber.Ctx) error {
 	utils.ResponseInterceptor(c, &webResponse)
 	return ctx.Status(fiber.StatusOK).JSON(webResponse)
 }
+
+// Note             godoc
+//
+//	@Summary		Get vehicle by id.
+//	@Description	Get vehicle by id.
+//	@Produce		application/json
+//	@Param			id			path	string	true	"vehicle_id"
+//	@Param			X-Tenant-ID	header	string	false	"DMS customer id"
+//	@Param			X-Timezone	header	string	false	"IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone"	example(Asia/Jakarta)
+//	@Tags			vehicle
+//	@Success		200	{object}	dto.JsonSuccess{data=dto.VehicleDetailResponse{}}	"Data"
+//	@Failure		400	{object}	dto.JsonBadRequest{}								"Validation error"
+//	@Failure		401	{object}	dto.JsonUnauthorized{}								"Tenant not resolved"
+//	@Failure		404	{object}	dto.JsonNotFound{}									"Data not found"
+//	@Failure		500	{object}	dto.JsonInternalServerError{}						"Internal server error"
+//	@Failure		502	{object}	dto.JsonBadGateway{}								"DMS failed"
+//	@Router			/vehicles/{id} [get]
+func (handler *DmsHandler) GetVehicleById(ctx *fiber.Ctx) error {
+	c, cancel := context.WithTimeout(ctx.Context(), 30*time.Second)
+	defer cancel()
+
+	var params dto.VehicleParams
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
+	if err := handler.validate.StructCtx(c, params); err != nil {
+		return err
+	}
+
+	response, err := handler.dmsService.GetVehicleById(c, params)
+	if err != nil {
+		return err
+	}
+
+	webResponse := dto.Response{
+		Code:   fiber.StatusOK,
+		Status: "OK",
+		Data:   response,
+	}
+	utils.ResponseInterceptor(c, &webResponse)
+	return ctx.Status(fiber.StatusOK).JSON(webResponse)
+}
+
+// Note             godoc
+//
+//	@Summary		Get driver of a vehicle.
+//	@Description	Get the driver currently assigned to a vehicle.
+//	@Produce		application/json
+//	@Param			id			path	string	true	"vehicle_id"
+//	@Param			X-Tenant-ID	header	string	false	"DMS customer id"
+//	@Param			X-Timezone	header	string	false	"IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone"	example(Asia/Jakarta)
+//	@Tags			vehicle
+//	@Success		200	{object}	dto.JsonSuccess{data=dto.DriverResponse{}}	"Data"
+//	@Failure		400	{object}	dto.JsonBadRequest{}						"Validation error"
+//	@Failure		401	{object}	dto.JsonUnauthorized{}						"Tenant not resolved"
+//	@Failure		404	{object}	dto.JsonNotFound{}							"Data not found"
+//	@Failure		500	{object}	dto.JsonInternalServerError{}				"Internal server error"
+//	@Failure		502	{object}	dto.JsonBadGateway{}						"DMS failed"
+//	@Router			/vehicles/{id}/driver [get]
+func (handler *DmsHandler) GetVehicleDriver(ctx *fiber.Ctx) error {
+	c, cancel := context.WithTimeout(ctx.Context(), 30*time.Second)
+	defer cancel()
+
+	var params dto.VehicleParams
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return exception.NewBadRequestHandler(err.Error())
+	}
+	if err := handler.validate.StructCtx(c, params); err != nil {
+		return err
+	}
+
+	response, err := handler.dmsService.GetVehicleDriver(c, params)
+	if err != nil {
+		return err
+	}
+
+	webResponse := dto.Response{
+		Code:   fiber.StatusOK,
+		Status: "OK",
+		Data:   response,
+	}
+	utils.ResponseInterceptor(c, &webResponse)
+	return ctx.Status(fiber.StatusOK).JSON(webResponse)
+}
+
+// Note             godoc
+//
+//	@Summary		Sync vehicles from DMS.
+//	@Description	Mirror the tenant vehicles from DMS into the local vehicles table.
+//	@Produce		application/json
+//	@Param			X-Tenant-ID	header	string	false	"DMS customer id"
+//	@Param			X-Timezone	header	string	false	"IANA timezone or offset of dates without one and of returned timestamps, default time.default_timezone"	example(Asia/Jakarta)
+//	@Tags			vehicle
+//	@Success		200	{object}	dto.JsonSuccess{data=dto.VehicleSyncRunResponse{}}	"Data"
+//	@Failure		401	{object}	dto.JsonUnauthorized{}								"Tenant not resolved"
+//	@Failure		409	{object}	dto.JsonConflict{}									"Sync already running"
+//	@Failure		500	{object}	dto.JsonInternalServerError{}						"Internal server error"
+//	@Failure		502	{object}	dto.JsonBadGateway{}								"DMS failed"
+//	@Router			/vehicles/sync [post]
+func (handler *DmsHandler) Sync(ctx *fiber.Ctx) error {
+	c, cancel := context.WithTimeout(ctx.Context(), 5*time.Minute)
+	defer cancel()
+
+	response, err := handler.vehicleSyncService.Sync(c, service.SyncTriggerManual)
+	if err != nil {
+		return err
+	}
+
+	webResponse := dto.Response{
+		Code:    fiber.StatusOK,
+		Status:  "OK",
+		Message: "Sync Successful",
+		Data:    response,
+	}
+	utils.ResponseInterceptor(c, &webResponse)
+	return ctx.Status(fiber.StatusOK).JSON(webResponse)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"scylla/dto"
+	"scylla/pkg/health"
+	"time"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+func (handler *HealthHandler) Route(app *fiber.App) {
+	app.Get("/healthz", handler.Live)
+	app.Get("/readyz", handler.Ready)
+}
+
+// Note             godoc
+//
+//	@Summary		Liveness probe.
+//	@Description	Reports that the process is up. It does not check dependencies.
+//	@Produce		application/json
+//	@Tags			health
+//	@Success		200	{object}	dto.HealthResponse	"Alive"
+//	@Router			/healthz [get]
+func (handler *HealthHandler) Live(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(dto.HealthResponse{Status: health.StatusUp})
+}
+
+// Note             godoc
+//
+//	@Summary		Readiness probe.
+//	@Description	Checks every registered dependency and reports the status and latency of each. Fails while the server is shutting down.
+//	@Produce		application/json
+//	@Tags			health
+//	@Success		200	{object}	dto.HealthResponse	"Ready"
+//	@Failure		503	{object}	dto.HealthResponse	"Not ready"
+//	@Router			/readyz [get]
+func (handler *HealthHandler) Ready(ctx *fiber.Ctx) error {
+	ready, results := handler.registry.Ready(ctx.Context())
+
+	response := dto.HealthResponse{
+		Status: health.StatusUp,
+		Checks: make(map[string]dto.HealthCheckResponse, len(results)),
+	}
+	for name, result := range results {
+		check := dto.HealthCheckResponse{
+			Status:    result.Status,
+			LatencyMs: float64(result.Latency) / float64(time.Millisecond),
+		}
+		if result.Err != nil {
+			check.Error = result.Err.Error()
+		}
+		response.Checks[name] = check
+	}
+
+	status := fiber.StatusOK
+	if !ready {
+		status = fiber.StatusServiceUnavailable
+		response.Status = health.StatusDown
+	}
+	return ctx.Status(status).JSON(response)
+}
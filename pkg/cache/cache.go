@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrMiss = errors.New("cache miss")
+
+// Entry is a cached value together with the time it was stored, so callers can
+// decide whether it is still fresh or only usable as a stale fallback.
+type Entry struct {
+	Value    []byte
+	StoredAt time.Time
+}
+
+func (e Entry) Age() time.Duration {
+	return time.Since(e.StoredAt)
+}
+
+// Store keeps serialized values by key. Values are plain bytes so the
+// in-process store can be swapped for a Redis-compatible one without touching
+// callers.
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, error)
+	Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	key       string
+	entry     Entry
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process Store holding at most maxEntries items. When
+// full, the least recently used item is evicted to make room, so keys built
+// from client input cannot grow it without limit.
+type MemoryStore struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List // most recently used first
+	maxEntries int
+	stop       chan struct{}
+	once       sync.Once
+}
+
+// NewMemoryStore returns an in-process Store bounded to maxEntries items, or
+// unbounded when it is 0. Expired items are dropped on read and by a janitor
+// running every sweepInterval until Close is called.
+func NewMemoryStore(sweepInterval time.Duration, maxEntries int) *MemoryStore {
+	store := &MemoryStore{
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+		stop:       make(chan struct{}),
+	}
+	if sweepInterval > 0 {
+		go store.janitor(sweepInterval)
+	}
+	return store
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.items[key]
+	if !ok {
+		return Entry{}, ErrMiss
+	}
+	item := element.Value.(*memoryItem)
+	if time.Now().After(item.expiresAt) {
+		s.remove(element)
+		return Entry{}, ErrMiss
+	}
+	s.order.MoveToFront(element)
+	return item.entry, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, entry Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := s.items[key]; ok {
+		item := element.Value.(*memoryItem)
+		item.entry, item.expiresAt = entry, expiresAt
+		s.order.MoveToFront(element)
+		return nil
+	}
+	if s.maxEntries > 0 && s.order.Len() >= s.maxEntries {
+		s.remove(s.order.Back())
+	}
+	s.items[key] = s.order.PushFront(&memoryItem{key: key, entry: entry, expiresAt: expiresAt})
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.items[key]; ok {
+		s.remove(element)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+func (s *MemoryStore) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+// remove drops element; s.mu must be held.
+func (s *MemoryStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.items, element.Value.(*memoryItem).key)
+}
+
+func (s *MemoryStore) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			s.mu.Lock()
+			for _, element := range s.items {
+				if now.After(element.Value.(*memoryItem).expiresAt) {
+					s.remove(element)
+				}
+			}
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
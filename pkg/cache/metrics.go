@@ -0,0 +1,22 @@
+package cache
+
+import "sync/atomic"
+
+// Metrics counts cache outcomes. Counters are safe for concurrent use.
+type Metrics struct {
+	Hits           atomic.Int64
+	Misses         atomic.Int64
+	StaleServed    atomic.Int64
+	Coalesced      atomic.Int64
+	UpstreamErrors atomic.Int64
+}
+
+func (m *Metrics) Snapshot() map[string]int64 {
+	return map[string]int64{
+		"hits":            m.Hits.Load(),
+		"misses":          m.Misses.Load(),
+		"stale_served":    m.StaleServed.Load(),
+		"coalesced":       m.Coalesced.Load(),
+		"upstream_errors": m.UpstreamErrors.Load(),
+	}
+}
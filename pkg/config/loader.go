@@ -1,39 +1,291 @@
 package config
 
 import (
-	"github.com/joho/godotenv"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// option declares a config key with its default value and the environment
+// variable that overrides it. Every option with an environment variable can
+// also be set with a --<key> flag.
+type option struct {
+	key   string
+	env   string
+	value interface{}
+	usage string
+}
+
+var options = []option{
+	{"server.port", "SERVER_PORT", 3000, "HTTP listen port"},
+	{"server.shutdown_delay", "SERVER_SHUTDOWN_DELAY", time.Duration(0), "time /readyz fails before the server stops accepting connections"},
+	{"server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", 30 * time.Second, "time allowed to drain requests and release resources on shutdown"},
+	{"log.level", "LOG_LEVEL", "info", "log level: debug, info, warn or error"},
+	{"database.host", "DB_HOST", "localhost", "Postgres host"},
+	{"database.port", "DB_PORT", 5432, "Postgres port"},
+	{"database.user", "DB_USER", "", "Postgres user"},
+	{"database.pass", "DB_PASS", "", "Postgres password"},
+	{"database.name", "DB_NAME", "", "Postgres database name"},
+	{"database.dsn", "DB_DSN", "", "full Postgres DSN or URL, replaces host/port/user/pass/name"},
+	{"database.ssl_mode", "DB_SSL_MODE", "disable", "disable, allow, prefer, require, verify-ca or verify-full"},
+	{"database.ssl_root_cert", "DB_SSL_ROOT_CERT", "", "CA certificate used by verify-ca and verify-full"},
+	{"database.ssl_cert", "DB_SSL_CERT", "", "client certificate"},
+	{"database.ssl_key", "DB_SSL_KEY", "", "client certificate key"},
+	{"database.application_name", "DB_APPLICATION_NAME", "scylla-api", "application_name reported to Postgres"},
+	{"database.statement_timeout", "DB_STATEMENT_TIMEOUT", time.Duration(0), "statement_timeout of every session, 0 disables it"},
+	{"database.max_idle_conns", "DB_MAX_IDLE_CONNS", 10, "idle connections kept in the pool"},
+	{"database.max_open_conns", "DB_MAX_OPEN_CONNS", 100, "maximum open connections"},
+	{"database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", 300 * time.Second, "maximum lifetime of a connection"},
+	{"database.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", time.Duration(0), "maximum idle time of a connection, 0 keeps them"},
+	{"database.connect_retries", "DB_CONNECT_RETRIES", 5, "connection attempts at startup after the first one fails"},
+	{"database.connect_backoff", "DB_CONNECT_BACKOFF", time.Second, "initial wait between connection attempts, doubled each time"},
+	{"database.log_level", "DB_LOG_LEVEL", "warn", "SQL log level: silent, error, warn or info"},
+	{"database.slow_threshold", "DB_SLOW_THRESHOLD", 200 * time.Millisecond, "queries slower than this are logged at warn"},
+	{"swagger.host", "SWAGGER_HOST", "", "public host shown in the Swagger docs"},
+	{"swagger.url", "SWAGGER_URL", "", "public base path shown in the Swagger docs"},
+	{"swagger.mode", "SWAGGER_MODE", "dev", "dev serves the docs for localhost"},
+	{"kong.url", "KONG_URL", "", "Kong gateway base URL"},
+	{"kong.default_cust_id", "KONG_DEFAULT_CUST_ID", "", "DMS customer id used when the request has no tenant"},
+	{"kong.trusted_proxies", "KONG_TRUSTED_PROXIES", []string{}, "comma separated IPs or CIDRs of gateways allowed to set X-Tenant-ID, empty rejects the header"},
+	{"dms_cache.ttl", "DMS_CACHE_TTL", 5 * time.Minute, "how long DMS responses are served from cache"},
+	{"dms_cache.stale_ttl", "DMS_CACHE_STALE_TTL", time.Hour, "how long expired DMS responses are kept as a fallback"},
+	{"dms_cache.max_entries", "DMS_CACHE_MAX_ENTRIES", 10000, "DMS responses kept in memory, the least recently used are evicted beyond it"},
+	{"vehicle_sync.interval", "VEHICLE_SYNC_INTERVAL", time.Hour, "vehicle sync interval, 0 disables the worker"},
+	{"vehicle_sync.page_size", "VEHICLE_SYNC_PAGE_SIZE", 100, "vehicles fetched per DMS page during sync"},
+	{"vehicle_sync.cust_ids", "VEHICLE_SYNC_CUST_IDS", []string{}, "comma separated DMS customer ids synced by the worker"},
+	{"obs.ak", "OBS_HUAWEI_AK", "", "OBS access key"},
+	{"obs.sk", "OBS_HUAWEI_SK", "", "OBS secret key"},
+	{"obs.endpoint", "OBS_HUAWEI_ENDPOINT", "", "OBS endpoint"},
+	{"obs.bucket", "OBS_HUAWEI_BUCKET", "", "OBS bucket"},
+	{"health.timeout", "HEALTH_TIMEOUT", 2 * time.Second, "timeout of each readiness check"},
+	{"health.check_kong", "HEALTH_CHECK_KONG", false, "include the Kong upstream in readiness"},
+	{"health.check_obs", "HEALTH_CHECK_OBS", false, "include the OBS bucket in readiness"},
+	{"tracing.exporter", "TRACING_EXPORTER", "none", "span exporter: none, stdout, file or otlp"},
+	{"tracing.endpoint", "TRACING_OTLP_ENDPOINT", "", "OTLP/HTTP collector host:port, empty uses OTEL_EXPORTER_OTLP_ENDPOINT"},
+	{"tracing.insecure", "TRACING_OTLP_INSECURE", false, "send OTLP over plain HTTP"},
+	{"tracing.file", "TRACING_FILE", "traces.jsonl", "file written by the file exporter"},
+	{"tracing.sample_ratio", "TRACING_SAMPLE_RATIO", 1.0, "share of new traces sampled, between 0 and 1"},
+	{"tracing.service_name", "TRACING_SERVICE_NAME", "scylla-api", "service.name reported with every span"},
+	{"pagination.cursor_secret", "PAGINATION_CURSOR_SECRET", "", "key signing list cursors, the same on every instance (required)"},
+	{"time.default_timezone", "DEFAULT_TIMEZONE", "UTC", "IANA timezone of dates without an offset and of response timestamps when a request sends no X-Timezone"},
+	{"runtime.cors_origins", "CORS_ORIGINS", []string{"*"}, "comma separated origins allowed by CORS"},
+	{"runtime.upstream_timeout", "UPSTREAM_TIMEOUT", 10 * time.Second, "timeout of each call to Kong"},
+	{"runtime.rate_limit.max", "RATE_LIMIT_MAX", 0, "requests per client per window, 0 disables rate limiting"},
+	{"runtime.rate_limit.window", "RATE_LIMIT_WINDOW", time.Minute, "rate limit window"},
+	{"runtime.features", "", map[string]bool{}, "feature flags, config file only"},
+}
+
+// envs maps each config key to its environment variable.
+var envs = func() map[string]string {
+	envs := make(map[string]string, len(options))
+	for _, opt := range options {
+		envs[opt.key] = opt.env
+	}
+	return envs
+}()
+
+// processEnv holds the variables the process started with. They win over
+// .env on every load, not only the first.
+var processEnv = func() map[string]bool {
+	env := make(map[string]bool)
+	for _, pair := range os.Environ() {
+		key, _, _ := strings.Cut(pair, "=")
+		env[key] = true
+	}
+	return env
+}()
+
+// dotenvKeys are the variables set from the last read of .env.
+var dotenvKeys = map[string]bool{}
+
+// loadDotenv applies .env over the environment the process started with.
+// Unlike godotenv.Load it also replaces the values an earlier read set and
+// unsets the ones since removed, so a reload sees edits to the file.
+func loadDotenv() error {
+	values, err := godotenv.Read()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for key := range dotenvKeys {
+		if _, ok := values[key]; !ok {
+			_ = os.Unsetenv(key)
+			delete(dotenvKeys, key)
+		}
+	}
+	for key, value := range values {
+		if processEnv[key] {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+		dotenvKeys[key] = true
+	}
+	return nil
+}
+
+var decodeErrorPattern = regexp.MustCompile(`^error decoding '([^']+)': (.*)$`)
+
+var (
+	loadErr error
+	once    sync.Once
 )
 
+// ValidationError lists every config key that is missing or invalid.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load reads the configuration once per process, layering defaults, an
+// optional YAML/TOML file, environment variables (a local .env is loaded when
+// present) and command-line flags, in increasing order of precedence. Later
+// calls return the current instance, which changes only through Reload.
+func Load() (*Config, error) {
+	once.Do(func() {
+		var conf *Config
+		conf, source, loadErr = load(os.Args[1:])
+		current.Store(conf)
+	})
+	return current.Load(), loadErr
+}
+
+// Get returns the current configuration and panics when it is invalid.
 func Get() *Config {
-	err := godotenv.Load()
+	conf, err := Load()
 	if err != nil {
 		panic(err)
 	}
-	return &Config{
-		Server: Server{
-			Port: os.Getenv("SERVER_PORT"),
-		},
-		Database: Database{
-			Host: os.Getenv("DB_HOST"),
-			Port: os.Getenv("DB_PORT"),
-			User: os.Getenv("DB_USER"),
-			Pass: os.Getenv("DB_PASS"),
-			Name: os.Getenv("DB_NAME"),
-		},
-		Swagger: Swagger{
-			Host: os.Getenv("SWAGGER_HOST"),
-			Url:  os.Getenv("SWAGGER_URL"),
-			Mode: os.Getenv("SWAGGER_MODE"),
-		},
-		Kong: Kong{
-			Url: os.Getenv("KONG_URL"),
-		},
-		Obs: ObsHuawei{
-			Ak:       os.Getenv("OBS_HUAWEI_AK"),
-			Sk:       os.Getenv("OBS_HUAWEI_SK"),
-			Endpoint: os.Getenv("OBS_HUAWEI_ENDPOINT"),
-			Bucket:   os.Getenv("OBS_HUAWEI_BUCKET"),
-		},
+	return conf
+}
+
+func load(args []string) (*Config, *viper.Viper, error) {
+	var problems []string
+
+	if err := loadDotenv(); err != nil {
+		problems = append(problems, fmt.Sprintf(".env: %s", err.Error()))
+	}
+
+	v := viper.New()
+	flags := pflag.NewFlagSet("api", pflag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	for _, opt := range options {
+		v.SetDefault(opt.key, opt.value)
+		if opt.env == "" {
+			continue
+		}
+		_ = v.BindEnv(opt.key, opt.env)
+		flags.String(opt.key, "", fmt.Sprintf("%s (env %s)", opt.usage, opt.env))
+		_ = v.BindPFlag(opt.key, flags.Lookup(opt.key))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, &ValidationError{Problems: append(problems, err.Error())}
+	}
+
+	if *configFile != "" {
+		v.SetConfigFile(*configFile)
+	} else {
+		v.SetConfigName("config")
+		v.AddConfigPath(".")
+		v.AddConfigPath("./config")
+	}
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			problems = append(problems, fmt.Sprintf("config file: %s", err.Error()))
+		}
+	}
+
+	conf := &Config{}
+	invalid := make(map[string]bool)
+	if err := v.Unmarshal(conf); err != nil {
+		var decodeErr *mapstructure.Error
+		if !errors.As(err, &decodeErr) {
+			return nil, nil, &ValidationError{Problems: append(problems, err.Error())}
+		}
+		for _, msg := range decodeErr.Errors {
+			if match := decodeErrorPattern.FindStringSubmatch(msg); match != nil {
+				invalid[match[1]] = true
+				msg = fmt.Sprintf("%s (%s): %s", match[1], envs[match[1]], match[2])
+			}
+			problems = append(problems, msg)
+		}
+	}
+
+	conf.VehicleSync.CustIds = trimList(conf.VehicleSync.CustIds)
+	conf.Runtime.CorsOrigins = trimList(conf.Runtime.CorsOrigins)
+	conf.Kong.TrustedProxies = trimList(conf.Kong.TrustedProxies)
+	if len(conf.VehicleSync.CustIds) == 0 && conf.Kong.DefaultCustId != "" {
+		conf.VehicleSync.CustIds = []string{conf.Kong.DefaultCustId}
+	}
+
+	for _, problem := range validate(conf) {
+		if !invalid[problem.key] {
+			problems = append(problems, problem.msg)
+		}
+	}
+	if conf.Health.CheckObs && conf.Obs.Endpoint == "" {
+		problems = append(problems, "health.check_obs (HEALTH_CHECK_OBS) requires obs.endpoint")
+	}
+	if len(problems) > 0 {
+		return nil, nil, &ValidationError{Problems: problems}
+	}
+
+	return conf, v, nil
+}
+
+type problem struct {
+	key string
+	msg string
+}
+
+func validate(conf *Config) (problems []problem) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		return fld.Tag.Get("mapstructure")
+	})
+
+	err := validate.Struct(conf)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	for _, e := range validationErrors {
+		key := e.Namespace()[strings.Index(e.Namespace(), ".")+1:]
+		rule := e.Tag()
+		if e.Param() != "" {
+			rule += "=" + e.Param()
+		}
+		problems = append(problems, problem{
+			key: key,
+			msg: fmt.Sprintf("%s (%s) fails %s, got %q", key, envs[key], rule, fmt.Sprint(e.Value())),
+		})
+	}
+	return problems
+}
+
+func trimList(list []string) []string {
+	trimmed := list[:0]
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			trimmed = append(trimmed, item)
+		}
 	}
+	return trimmed
 }
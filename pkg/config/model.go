@@ -1,38 +1,121 @@
 package config
 
+import "time"
+
 type Config struct {
-	Server   Server
-	Database Database
-	Swagger  Swagger
-	Kong     Kong
-	Obs      ObsHuawei
+	Server      Server      `mapstructure:"server"`
+	Log         Log         `mapstructure:"log"`
+	Database    Database    `mapstructure:"database"`
+	Swagger     Swagger     `mapstructure:"swagger"`
+	Kong        Kong        `mapstructure:"kong"`
+	DmsCache    DmsCache    `mapstructure:"dms_cache"`
+	VehicleSync VehicleSync `mapstructure:"vehicle_sync"`
+	Obs         ObsHuawei   `mapstructure:"obs"`
+	Health      Health      `mapstructure:"health"`
+	Tracing     Tracing     `mapstructure:"tracing"`
+	Pagination  Pagination  `mapstructure:"pagination"`
+	Time        Time        `mapstructure:"time"`
+	Runtime     Runtime     `mapstructure:"runtime"`
 }
 
 type Server struct {
-	Port string
+	Port            int           `mapstructure:"port" validate:"required,min=1,max=65535"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" validate:"min=1s"`
+	ShutdownDelay   time.Duration `mapstructure:"shutdown_delay" validate:"min=0s,ltfield=ShutdownTimeout"`
+}
+
+type Log struct {
+	Level string `mapstructure:"level" validate:"oneof=debug info warn error"`
 }
 
 type Database struct {
-	Host string
-	Port string
-	User string
-	Pass string
-	Name string
+	Dsn              string        `mapstructure:"dsn"`
+	Host             string        `mapstructure:"host" validate:"required_without=Dsn"`
+	Port             int           `mapstructure:"port" validate:"required_without=Dsn,omitempty,min=1,max=65535"`
+	User             string        `mapstructure:"user" validate:"required_without=Dsn"`
+	Pass             string        `mapstructure:"pass"`
+	Name             string        `mapstructure:"name" validate:"required_without=Dsn"`
+	SslMode          string        `mapstructure:"ssl_mode" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	SslRootCert      string        `mapstructure:"ssl_root_cert" validate:"required_if=SslMode verify-ca,required_if=SslMode verify-full"`
+	SslCert          string        `mapstructure:"ssl_cert" validate:"required_with=SslKey"`
+	SslKey           string        `mapstructure:"ssl_key" validate:"required_with=SslCert"`
+	ApplicationName  string        `mapstructure:"application_name"`
+	StatementTimeout time.Duration `mapstructure:"statement_timeout" validate:"min=0s"`
+	MaxIdleConns     int           `mapstructure:"max_idle_conns" validate:"min=0"`
+	MaxOpenConns     int           `mapstructure:"max_open_conns" validate:"min=1"`
+	ConnMaxLifetime  time.Duration `mapstructure:"conn_max_lifetime" validate:"min=0s"`
+	ConnMaxIdleTime  time.Duration `mapstructure:"conn_max_idle_time" validate:"min=0s"`
+	ConnectRetries   int           `mapstructure:"connect_retries" validate:"min=0"`
+	ConnectBackoff   time.Duration `mapstructure:"connect_backoff" validate:"min=0s"`
+	LogLevel         string        `mapstructure:"log_level" validate:"oneof=silent error warn info"`
+	SlowThreshold    time.Duration `mapstructure:"slow_threshold" validate:"min=0s"`
 }
 
 type Swagger struct {
-	Host string
-	Url  string
-	Mode string
+	Host string `mapstructure:"host"`
+	Url  string `mapstructure:"url"`
+	Mode string `mapstructure:"mode"`
 }
 
 type Kong struct {
-	Url string
+	Url           string `mapstructure:"url" validate:"required,url"`
+	DefaultCustId string `mapstructure:"default_cust_id"`
+	// TrustedProxies are the gateways whose X-Tenant-ID header is believed.
+	TrustedProxies []string `mapstructure:"trusted_proxies" validate:"dive,cidr|ip"`
+}
+
+type DmsCache struct {
+	Ttl        time.Duration `mapstructure:"ttl" validate:"min=1s"`
+	StaleTtl   time.Duration `mapstructure:"stale_ttl" validate:"min=0s"`
+	MaxEntries int           `mapstructure:"max_entries" validate:"min=1"`
+}
+
+type VehicleSync struct {
+	Interval time.Duration `mapstructure:"interval" validate:"min=0s"`
+	PageSize int           `mapstructure:"page_size" validate:"min=1,max=1000"`
+	CustIds  []string      `mapstructure:"cust_ids"`
 }
 
 type ObsHuawei struct {
-	Ak       string
-	Sk       string
-	Endpoint string
-	Bucket   string
+	Ak       string `mapstructure:"ak"`
+	Sk       string `mapstructure:"sk"`
+	Endpoint string `mapstructure:"endpoint"`
+	Bucket   string `mapstructure:"bucket"`
+}
+
+type Health struct {
+	Timeout   time.Duration `mapstructure:"timeout" validate:"min=100ms"`
+	CheckKong bool          `mapstructure:"check_kong"`
+	CheckObs  bool          `mapstructure:"check_obs"`
+}
+
+type Tracing struct {
+	Exporter    string  `mapstructure:"exporter" validate:"oneof=none stdout file otlp"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	File        string  `mapstructure:"file" validate:"required_if=Exporter file"`
+	SampleRatio float64 `mapstructure:"sample_ratio" validate:"min=0,max=1"`
+	ServiceName string  `mapstructure:"service_name" validate:"required"`
+}
+
+type Pagination struct {
+	CursorSecret string `mapstructure:"cursor_secret" validate:"required"`
+}
+
+type Time struct {
+	DefaultTimezone string `mapstructure:"default_timezone" validate:"required,timezone"`
+}
+
+// Runtime holds the settings that take effect without a restart when the
+// configuration is reloaded. Read them through Get at use time.
+type Runtime struct {
+	CorsOrigins     []string        `mapstructure:"cors_origins"`
+	UpstreamTimeout time.Duration   `mapstructure:"upstream_timeout" validate:"min=1s"`
+	RateLimit       RateLimit       `mapstructure:"rate_limit"`
+	Features        map[string]bool `mapstructure:"features"`
+}
+
+type RateLimit struct {
+	Max    int           `mapstructure:"max" validate:"min=0"`
+	Window time.Duration `mapstructure:"window" validate:"min=1s"`
 }
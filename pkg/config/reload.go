@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+var (
+	current atomic.Pointer[Config]
+	source  *viper.Viper
+
+	reloadMu    sync.Mutex
+	subscribeMu sync.Mutex
+	subscribers []func(old, new *Config)
+)
+
+// Subscribe registers fn to be called after every successful reload with the
+// previous and the new configuration. Callbacks run one at a time on the
+// reloading goroutine.
+func Subscribe(fn func(old, new *Config)) {
+	subscribeMu.Lock()
+	defer subscribeMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Feature reports whether the runtime feature flag name is enabled.
+func Feature(name string) bool {
+	return Get().Runtime.Features[name]
+}
+
+// Reload rebuilds the configuration from every source and swaps it in
+// atomically. An invalid configuration is rejected and the current one stays
+// live.
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	conf, _, err := load(os.Args[1:])
+	if err != nil {
+		slog.Error("config reload rejected, keeping the current config", slog.String("error", err.Error()))
+		return err
+	}
+
+	old := current.Swap(conf)
+
+	subscribeMu.Lock()
+	callbacks := append([]func(old, new *Config){}, subscribers...)
+	subscribeMu.Unlock()
+	for _, fn := range callbacks {
+		fn(old, conf)
+	}
+
+	slog.Info("config reloaded")
+	return nil
+}
+
+// Watch reloads the configuration whenever the config file changes or the
+// process receives SIGHUP, until ctx is done.
+func Watch(ctx context.Context) {
+	if source != nil && source.ConfigFileUsed() != "" {
+		source.OnConfigChange(func(event fsnotify.Event) {
+			if ctx.Err() == nil {
+				_ = Reload()
+			}
+		})
+		source.WatchConfig()
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				_ = Reload()
+			}
+		}
+	}()
+}
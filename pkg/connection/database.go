@@ -1,40 +1,137 @@
 package connection
 
 import (
+	"context"
 	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"log/slog"
+	"net/url"
 	"scylla/pkg/config"
 	"scylla/pkg/engine"
 	"scylla/pkg/exception"
+	"scylla/pkg/logger"
+	"scylla/pkg/metrics"
+	"scylla/pkg/tracing"
+	"strconv"
+	"strings"
 	"time"
 )
 
-func GetDatabase(conf config.Database) *gorm.DB {
-	sqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		conf.Host, conf.Port, conf.User, conf.Pass, conf.Name)
+const maxConnectBackoff = 30 * time.Second
 
-	db, err := gorm.Open(postgres.Open(sqlInfo), &gorm.Config{
-		PrepareStmt:            true,
-		SkipDefaultTransaction: true,
-		Logger:                 logger.Default.LogMode(logger.Info),
-	})
+func GetDatabase(conf config.Database, log *slog.Logger) *gorm.DB {
+	connConfig, err := pgx.ParseConfig(buildDsn(conf))
 	if err != nil {
 		panic(exception.NewInternalServerErrorHandler(err.Error()))
 	}
+	if _, ok := connConfig.RuntimeParams["application_name"]; !ok && conf.ApplicationName != "" {
+		connConfig.RuntimeParams["application_name"] = conf.ApplicationName
+	}
+	if conf.StatementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(conf.StatementTimeout.Milliseconds(), 10)
+	}
+
+	connection := stdlib.OpenDB(*connConfig)
+	connection.SetMaxIdleConns(conf.MaxIdleConns)
+	connection.SetMaxOpenConns(conf.MaxOpenConns)
+	connection.SetConnMaxLifetime(conf.ConnMaxLifetime)
+	connection.SetConnMaxIdleTime(conf.ConnMaxIdleTime)
+
+	backoff := conf.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = connection.PingContext(ctx)
+		cancel()
+		if err == nil || attempt >= conf.ConnectRetries {
+			break
+		}
+		log.Warn("database not reachable, retrying",
+			slog.Int("attempt", attempt+1),
+			slog.Int("attempts", conf.ConnectRetries+1),
+			slog.String("error", err.Error()),
+			slog.Duration("backoff", backoff))
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+	if err != nil {
+		panic(exception.NewInternalServerErrorHandler(err.Error()))
+	}
+
+	gormLogger := logger.NewGormLogger(log, conf)
+	config.Subscribe(func(old, new *config.Config) {
+		if old.Database.LogLevel != new.Database.LogLevel || old.Database.SlowThreshold != new.Database.SlowThreshold {
+			gormLogger.Configure(new.Database)
+		}
+	})
 
-	connection, err := db.DB()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: connection}), &gorm.Config{
+		PrepareStmt:            true,
+		SkipDefaultTransaction: true,
+		Logger:                 gormLogger,
+	})
 	if err != nil {
 		panic(exception.NewInternalServerErrorHandler(err.Error()))
 	}
 
-	connection.SetMaxIdleConns(10)
-	connection.SetMaxOpenConns(100)
-	connection.SetConnMaxLifetime(time.Second * time.Duration(300))
+	for _, plugin := range []gorm.Plugin{metrics.GormPlugin{}, tracing.GormPlugin{}} {
+		if err := db.Use(plugin); err != nil {
+			panic(exception.NewInternalServerErrorHandler(err.Error()))
+		}
+	}
 
 	engine.Instance = db
 
-	fmt.Println("🚀 Connected Successfully to the Database")
+	log.Info("connected to the database")
 	return db
 }
+
+// buildDsn returns conf.Dsn, or a key/value DSN built from the individual
+// settings when it is empty. SSL settings are added unless the DSN already
+// carries them.
+func buildDsn(conf config.Database) string {
+	ssl := [][2]string{
+		{"sslmode", conf.SslMode},
+		{"sslrootcert", conf.SslRootCert},
+		{"sslcert", conf.SslCert},
+		{"sslkey", conf.SslKey},
+	}
+
+	if strings.HasPrefix(conf.Dsn, "postgres://") || strings.HasPrefix(conf.Dsn, "postgresql://") {
+		dsn, err := url.Parse(conf.Dsn)
+		if err != nil {
+			return conf.Dsn
+		}
+		query := dsn.Query()
+		for _, param := range ssl {
+			if !query.Has(param[0]) && param[1] != "" {
+				query.Set(param[0], param[1])
+			}
+		}
+		dsn.RawQuery = query.Encode()
+		return dsn.String()
+	}
+
+	params := ssl
+	if conf.Dsn == "" {
+		params = append([][2]string{
+			{"host", conf.Host},
+			{"port", strconv.Itoa(conf.Port)},
+			{"user", conf.User},
+			{"password", conf.Pass},
+			{"dbname", conf.Name},
+		}, ssl...)
+	}
+
+	dsn := []string{conf.Dsn}
+	for _, param := range params {
+		if param[1] == "" || strings.Contains(conf.Dsn, param[0]+"=") {
+			continue
+		}
+		value := strings.ReplaceAll(strings.ReplaceAll(param[1], `\`, `\\`), `'`, `\'`)
+		dsn = append(dsn, fmt.Sprintf("%s='%s'", param[0], value))
+	}
+	return strings.TrimSpace(strings.Join(dsn, " "))
+}
@@ -0,0 +1,21 @@
+package exception
+
+type BadGatewayErrorStruct struct {
+	ErrorMsg string
+	Code     Code
+}
+
+func NewBadGatewayHandler(msg string) *BadGatewayErrorStruct {
+	return &BadGatewayErrorStruct{
+		ErrorMsg: msg,
+	}
+}
+
+func (e *BadGatewayErrorStruct) Error() string {
+	return e.ErrorMsg
+}
+
+func (e *BadGatewayErrorStruct) WithCode(code Code) *BadGatewayErrorStruct {
+	e.Code = code
+	return e
+}
@@ -2,6 +2,7 @@ package exception
 
 type BadRequestErrorStruct struct {
 	ErrorMsg string
+	Code     Code
 }
 
 func NewBadRequestHandler(msg string) *BadRequestErrorStruct {
@@ -13,3 +14,12 @@ func NewBadRequestHandler(msg string) *BadRequestErrorStruct {
 func (e *BadRequestErrorStruct) Error() string {
 	return e.ErrorMsg
 }
+
+func (e *BadRequestErrorStruct) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e *BadRequestErrorStruct) WithCode(code Code) *BadRequestErrorStruct {
+	e.Code = code
+	return e
+}
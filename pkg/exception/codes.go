@@ -0,0 +1,91 @@
+package exception
+
+import (
+	"strings"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details.
+const MIMEProblemJSON = "application/problem+json"
+
+// Code is the stable, machine-readable identifier of an error returned in the
+// "code" member of problem+json responses. Clients branch on it instead of on
+// messages, so a code is never renamed once published. Typed errors carry one
+// set with WithCode; errors without a code report the generic code of their
+// kind.
+type Code string
+
+// Generic codes, used when an error carries no more specific one.
+const (
+	CodeBadRequest       Code = "BAD_REQUEST"
+	CodeValidationFailed Code = "VALIDATION_FAILED"
+	CodeUnauthorized     Code = "UNAUTHORIZED"
+	CodeNotFound         Code = "NOT_FOUND"
+	CodeConflict         Code = "CONFLICT"
+	CodeInternal         Code = "INTERNAL_ERROR"
+)
+
+// Domain codes. FromDatabase derives <RECORD>_NOT_FOUND, <COLUMN>_TAKEN and
+// <RECORD>_CONFLICT from the record and column names, which yields the ones
+// marked derived below.
+const (
+	CodeCustomerNotFound          Code = "CUSTOMER_NOT_FOUND" // derived
+	CodeEmailTaken                Code = "EMAIL_TAKEN"        // derived
+	CodeUsernameTaken             Code = "USERNAME_TAKEN"     // derived
+	CodeVehicleNotFound           Code = "VEHICLE_NOT_FOUND"
+	CodeDriverNotFound            Code = "DRIVER_NOT_FOUND"
+	CodeVehicleAssignmentNotFound Code = "VEHICLE_ASSIGNMENT_NOT_FOUND"
+	CodeVehicleAssignmentConflict Code = "VEHICLE_ASSIGNMENT_CONFLICT" // derived
+	CodeVehicleAlreadyAssigned    Code = "VEHICLE_ALREADY_ASSIGNED"
+	CodeVehicleSyncRunning        Code = "VEHICLE_SYNC_RUNNING"
+	CodeTenantRequired            Code = "TENANT_REQUIRED"
+	CodeUntrustedTenant           Code = "UNTRUSTED_TENANT"
+	CodeInvalidDateRange          Code = "INVALID_DATE_RANGE"
+	CodeInvalidFileType           Code = "INVALID_FILE_TYPE"
+	CodeRouteNotFound             Code = "ROUTE_NOT_FOUND"
+	CodeUpstreamError             Code = "UPSTREAM_ERROR"
+	CodeInvalidSort               Code = "INVALID_SORT"
+	CodeInvalidCursor             Code = "INVALID_CURSOR"
+	CodeInvalidFilter             Code = "INVALID_FILTER"
+	CodeInvalidFields             Code = "INVALID_FIELDS"
+	CodeInvalidInclude            Code = "INVALID_INCLUDE"
+	CodeInvalidTimezone           Code = "INVALID_TIMEZONE"
+)
+
+// titles are the short, occurrence-independent summaries sent as "title".
+// Codes missing here use the reason phrase of their HTTP status.
+var titles = map[Code]string{
+	CodeValidationFailed:          "Validation failed",
+	CodeCustomerNotFound:          "Customer not found",
+	CodeEmailTaken:                "Email has already been taken",
+	CodeUsernameTaken:             "Username has already been taken",
+	CodeVehicleNotFound:           "Vehicle not found",
+	CodeDriverNotFound:            "Driver not found",
+	CodeVehicleAssignmentNotFound: "Vehicle assignment not found",
+	CodeVehicleAssignmentConflict: "Vehicle assignment overlaps an existing one",
+	CodeVehicleAlreadyAssigned:    "Vehicle is already assigned",
+	CodeVehicleSyncRunning:        "Vehicle sync is already running",
+	CodeTenantRequired:            "Tenant could not be resolved",
+	CodeUntrustedTenant:           "Tenant header from an untrusted client",
+	CodeInvalidDateRange:          "Invalid date range",
+	CodeInvalidFileType:           "Invalid file type",
+	CodeRouteNotFound:             "Route not found",
+	CodeUpstreamError:             "Upstream service error",
+	CodeInvalidSort:               "Invalid sort parameter",
+	CodeInvalidCursor:             "Invalid pagination cursor",
+	CodeInvalidFilter:             "Invalid filter parameter",
+	CodeInvalidFields:             "Invalid fields parameter",
+	CodeInvalidInclude:            "Invalid include parameter",
+	CodeInvalidTimezone:           "Invalid timezone",
+}
+
+// codeFor builds a code from a record or column name and a suffix, e.g.
+// ("vehicle assignment", "NOT_FOUND") is VEHICLE_ASSIGNMENT_NOT_FOUND.
+func codeFor(name, suffix string) Code {
+	name = strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", ".", "_").Replace(strings.TrimSpace(name)))
+	return Code(name + "_" + suffix)
+}
+
+// problemType is the "type" URI of a code, e.g. urn:problem:customer-not-found.
+func problemType(code Code) string {
+	return "urn:problem:" + strings.ReplaceAll(strings.ToLower(string(code)), "_", "-")
+}
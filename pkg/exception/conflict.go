@@ -0,0 +1,32 @@
+package exception
+
+type ConflictErrorStruct struct {
+	ErrorMsg string
+	Code     Code
+	// Fields lists the columns of a violated unique constraint, if any.
+	Fields []string
+	Err    error
+}
+
+func NewConflictHandler(msg string) *ConflictErrorStruct {
+	return &ConflictErrorStruct{
+		ErrorMsg: msg,
+	}
+}
+
+func (e *ConflictErrorStruct) Error() string {
+	return e.ErrorMsg
+}
+
+func (e *ConflictErrorStruct) Unwrap() error {
+	return e.Err
+}
+
+func (e *ConflictErrorStruct) Is(target error) bool {
+	return target == ErrConflict
+}
+
+func (e *ConflictErrorStruct) WithCode(code Code) *ConflictErrorStruct {
+	e.Code = code
+	return e
+}
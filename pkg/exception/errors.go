@@ -0,0 +1,71 @@
+package exception
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Sentinels for errors.Is. Every typed error of this package matches the
+// sentinel of its kind, so callers can test the kind without caring about the
+// message: errors.Is(err, exception.ErrNotFound).
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+const (
+	pgUniqueViolation    = "23505"
+	pgExclusionViolation = "23P01"
+)
+
+var pgKeyPattern = regexp.MustCompile(`^Key \(([^)]+)\)`)
+
+// FromDatabase maps the errors GORM and Postgres return to the typed errors of
+// this package: a missing record becomes NotFound and unique or exclusion
+// constraint violations become Conflict naming the offending columns. Other
+// errors are returned unchanged. what names the record in messages and codes.
+func FromDatabase(err error, what string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &NotFoundErrorStruct{ErrorMsg: what + " not found", Code: codeFor(what, "NOT_FOUND"), Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		// the detail also carries the duplicate value; only the columns are
+		// passed on so it does not leak into responses or logs
+		fields := []string{pgErr.ConstraintName}
+		if match := pgKeyPattern.FindStringSubmatch(pgErr.Detail); match != nil {
+			fields = strings.Split(match[1], ", ")
+		}
+		code := codeFor(what, "CONFLICT")
+		if len(fields) == 1 {
+			code = codeFor(fields[0], "TAKEN")
+		}
+		return &ConflictErrorStruct{
+			ErrorMsg: fmt.Sprintf("%s has already been taken", strings.Join(fields, ", ")),
+			Code:     code,
+			Fields:   fields,
+			Err:      err,
+		}
+	case pgExclusionViolation:
+		return &ConflictErrorStruct{
+			ErrorMsg: fmt.Sprintf("%s conflicts with an existing %s", what, what),
+			Code:     codeFor(what, "CONFLICT"),
+			Err:      err,
+		}
+	}
+	return err
+}
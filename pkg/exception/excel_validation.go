@@ -1,18 +1,29 @@
 package exception
 
-import "fmt"
+import (
+	"fmt"
+	"scylla/dto"
+)
 
 type ExcelValidation struct {
 	Errors map[string][]string
+	// Fields holds the same failures with the rule that produced each one.
+	Fields []dto.FieldError
 }
 
-func (e *ExcelValidation) AddHandler(field string, row int, message string) {
+func (e *ExcelValidation) AddHandler(field string, rule string, row int, message string) {
 	if e.Errors == nil {
 		e.Errors = make(map[string][]string)
 	}
-	e.Errors[field] = append(e.Errors[field], fmt.Sprintf("%s row %d", message, row))
+	message = fmt.Sprintf("%s row %d", message, row)
+	e.Errors[field] = append(e.Errors[field], message)
+	e.Fields = append(e.Fields, dto.FieldError{Field: field, Rule: rule, Message: message})
 }
 
 func (e *ExcelValidation) Error() string {
 	return "validation errors"
 }
+
+func (e *ExcelValidation) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -1,19 +1,35 @@
 package exception
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"scylla/dto"
+	"scylla/pkg/utils"
 	"strings"
 	"unicode"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
 )
 
-func ExceptionHandlers(ctx *fiber.Ctx, err error) error {
+// ExceptionHandlers returns the error handler rendering err returned or
+// panicked by a handler, translating validation messages with the
+// translator of validate. Typed errors are matched with errors.As so they may
+// arrive wrapped; errors that only match a sentinel fall back to its status,
+// and anything else is logged and reported as a 500 without its message. See
+// render for the response body.
+func ExceptionHandlers(validate *utils.Validator) fiber.ErrorHandler {
+	return func(ctx *fiber.Ctx, err error) error {
+		return handle(ctx, err, validate)
+	}
+}
+
+func handle(ctx *fiber.Ctx, err error, validate *utils.Validator) error {
 	if notFoundError(ctx, err) {
 		return nil
-	} else if validationError(ctx, err) {
+	} else if validationError(ctx, err, validate) {
 		return nil
 	} else if excelValidation(ctx, err) {
 		return nil
@@ -21,16 +37,68 @@ func ExceptionHandlers(ctx *fiber.Ctx, err error) error {
 		return nil
 	} else if unauthorizedError(ctx, err) {
 		return nil
+	} else if conflictError(ctx, err) {
+		return nil
+	} else if badGatewayError(ctx, err) {
+		return nil
+	} else if internalServerError(ctx, err) {
+		return nil
+	} else if fiberError(ctx, err) {
+		return nil
+	} else if sentinelError(ctx, err) {
+		return nil
 	} else {
-		internalServerError(ctx, err)
+		unknownError(ctx, err)
 		return nil
 	}
 }
 
-func validationError(ctx *fiber.Ctx, err interface{}) bool {
+// Status returns the status handle answers err with, for middleware that
+// reports a request before the error is rendered.
+func Status(err error) int {
+	var (
+		notFound     *NotFoundErrorStruct
+		validation   validator.ValidationErrors
+		excel        *ExcelValidation
+		badRequest   *BadRequestErrorStruct
+		unauthorized *UnauthorizedErrorStruct
+		conflict     *ConflictErrorStruct
+		badGateway   *BadGatewayErrorStruct
+		internal     *InternalServerErrorStruct
+		fiberErr     *fiber.Error
+	)
+	switch {
+	case errors.As(err, &notFound):
+		return fiber.StatusNotFound
+	case errors.As(err, &validation), errors.As(err, &excel), errors.As(err, &badRequest):
+		return fiber.StatusBadRequest
+	case errors.As(err, &unauthorized):
+		return fiber.StatusUnauthorized
+	case errors.As(err, &conflict):
+		return fiber.StatusConflict
+	case errors.As(err, &badGateway):
+		return fiber.StatusBadGateway
+	case errors.As(err, &internal):
+		return fiber.StatusInternalServerError
+	case errors.As(err, &fiberErr):
+		return fiberErr.Code
+	case errors.Is(err, ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return fiber.StatusConflict
+	case errors.Is(err, ErrValidation):
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
 
-	if castedObject, ok := err.(validator.ValidationErrors); ok {
+func validationError(ctx *fiber.Ctx, err error, validate *utils.Validator) bool {
+	var castedObject validator.ValidationErrors
+	if errors.As(err, &castedObject) {
 		report := make(map[string]string)
+		fieldErrors := make([]dto.FieldError, 0, len(castedObject))
+		acceptLanguage := ctx.Get(fiber.HeaderAcceptLanguage)
+		ctx.Vary(fiber.HeaderAcceptLanguage)
 		var fieldName string
 
 		for _, e := range castedObject {
@@ -42,128 +110,232 @@ func validationError(ctx *fiber.Ctx, err interface{}) bool {
 			} else {
 				fieldName = e.Field()
 			}
-			switch e.Tag() {
-			case "required":
-				report[fieldName] = fmt.Sprintf("%s is required", fieldName)
-			case "email":
-				report[fieldName] = fmt.Sprintf("%s is not valid email", fieldName)
-			case "gte":
-				report[fieldName] = fmt.Sprintf("%s value must be greater than %s", fieldName, e.Param())
-			case "lte":
-				report[fieldName] = fmt.Sprintf("%s value must be lower than %s", fieldName, e.Param())
-			case "unique":
-				report[fieldName] = fmt.Sprintf("%s has already been taken", fieldName)
-			case "max":
-				report[fieldName] = fmt.Sprintf("%s value must be lower than %s", fieldName, e.Param())
-			case "min":
-				report[fieldName] = fmt.Sprintf("%s value must be greater than %s", fieldName, e.Param())
-			case "numeric":
-				report[fieldName] = fmt.Sprintf("%s value must be numeric", fieldName)
-			case "number":
-				report[fieldName] = fmt.Sprintf("%s value must be number", fieldName)
-			case "oneof":
-				report[fieldName] = fmt.Sprintf("%s value must be %s", fieldName, e.Param())
-			case "len":
-				report[fieldName] = fmt.Sprintf("%s value must be exactly %s characters long", fieldName, e.Param())
-			case "alphanum":
-				report[fieldName] = fmt.Sprintf("%s value must be char and numeric %s", fieldName, e.Param())
-			case "sliceString":
-				report[fieldName] = fmt.Sprintf("%s value ​​in the array cannot be empty is string", fieldName)
-			case "dive":
-				report[fieldName] = fmt.Sprintf("%s value ​​in the array cannot be empty", fieldName)
-			case "datetime":
-				report[fieldName] = fmt.Sprintf("%s value must be date (yyyy-mm-dd)", fieldName)
-			case "required_if":
-				report[fieldName] = fmt.Sprintf("%s must be filled in if %s", fieldName, e.Param())
-			case "sliceInt":
-				report[fieldName] = fmt.Sprintf("%s value ​​in the array cannot be empty is int", fieldName)
-			case "equal":
-				report[fieldName] = fmt.Sprintf("%s and %s do not match do not match", fieldName, e.Param())
-			case "image":
-				report[fieldName] = fmt.Sprintf("%s file must be of type jpg, jpeg, png", fieldName)
-			case "base64Image":
-				report[fieldName] = fmt.Sprintf("%s value must be base64 encoded image", fieldName)
-			}
+			message := validate.TranslateError(e, acceptLanguage)
+			report[fieldName] = message
+			fieldErrors = append(fieldErrors, dto.FieldError{Field: fieldName, Rule: e.Tag(), Message: message})
 		}
 
-		ctx.Status(fiber.StatusBadRequest).JSON(dto.Error{
-			Code:    fiber.StatusBadRequest,
-			Status:  "BAD REQUEST",
-			Errors:  report,
-			TraceID: ctx.Locals("requestid").(string),
+		render(ctx, failure{
+			status: fiber.StatusBadRequest,
+			code:   CodeValidationFailed,
+			detail: "request has invalid fields",
+			legacy: report,
+			fields: fieldErrors,
+		})
+		return true
+	}
+	return false
+}
+
+func notFoundError(ctx *fiber.Ctx, err error) bool {
+	var exception *NotFoundErrorStruct
+	if errors.As(err, &exception) {
+		render(ctx, failure{
+			status: fiber.StatusNotFound,
+			code:   orDefault(exception.Code, CodeNotFound),
+			detail: exception.Error(),
+		})
+		return true
+	}
+	return false
+}
+
+func badRequestError(ctx *fiber.Ctx, err error) bool {
+	var exception *BadRequestErrorStruct
+	if errors.As(err, &exception) {
+		render(ctx, failure{
+			status: fiber.StatusBadRequest,
+			code:   orDefault(exception.Code, CodeBadRequest),
+			detail: exception.Error(),
+		})
+		return true
+	}
+	return false
+}
+
+func excelValidation(ctx *fiber.Ctx, err error) bool {
+	var exception *ExcelValidation
+	if errors.As(err, &exception) {
+		render(ctx, failure{
+			status: fiber.StatusBadRequest,
+			code:   CodeValidationFailed,
+			detail: "uploaded file has invalid rows",
+			legacy: exception.Errors,
+			fields: exception.Fields,
 		})
 		return true
 	}
 	return false
 }
 
-func notFoundError(ctx *fiber.Ctx, err interface{}) bool {
-	exception, ok := err.(*NotFoundErrorStruct)
-	if ok {
-		ctx.Status(fiber.StatusNotFound).JSON(dto.Error{
-			Code:    fiber.StatusNotFound,
-			Status:  "NOT FOUND",
-			Errors:  exception.Error(),
-			TraceID: ctx.Locals("requestid").(string),
+func unauthorizedError(ctx *fiber.Ctx, err error) bool {
+	var exception *UnauthorizedErrorStruct
+	if errors.As(err, &exception) {
+		render(ctx, failure{
+			status: fiber.StatusUnauthorized,
+			code:   orDefault(exception.Code, CodeUnauthorized),
+			detail: exception.Error(),
 		})
 		return true
 	}
 	return false
 }
 
-func badRequestError(ctx *fiber.Ctx, err interface{}) bool {
-	exception, ok := err.(*BadRequestErrorStruct)
-	if ok {
-		ctx.Status(fiber.StatusBadRequest).JSON(dto.Error{
-			Code:    fiber.StatusBadRequest,
-			Status:  "BAD REQUESTsss",
-			Errors:  exception.Error(),
-			TraceID: ctx.Locals("requestid").(string),
+func conflictError(ctx *fiber.Ctx, err error) bool {
+	var exception *ConflictErrorStruct
+	if errors.As(err, &exception) {
+		var fields []dto.FieldError
+		for _, field := range exception.Fields {
+			fields = append(fields, dto.FieldError{
+				Field:   field,
+				Rule:    "unique",
+				Message: fmt.Sprintf("%s has already been taken", field),
+			})
+		}
+		render(ctx, failure{
+			status: fiber.StatusConflict,
+			code:   orDefault(exception.Code, CodeConflict),
+			detail: exception.Error(),
+			fields: fields,
 		})
 		return true
 	}
 	return false
 }
 
-func excelValidation(ctx *fiber.Ctx, err interface{}) bool {
-	exception, ok := err.(*ExcelValidation)
-	if ok {
-		ctx.Status(fiber.StatusBadRequest).JSON(dto.Error{
-			Code:    fiber.StatusBadRequest,
-			Status:  "BAD REQUEST",
-			Errors:  exception.Errors,
-			TraceID: ctx.Locals("requestid").(string),
+func badGatewayError(ctx *fiber.Ctx, err error) bool {
+	var exception *BadGatewayErrorStruct
+	if errors.As(err, &exception) {
+		render(ctx, failure{
+			status: fiber.StatusBadGateway,
+			code:   orDefault(exception.Code, CodeUpstreamError),
+			detail: exception.Error(),
 		})
 		return true
 	}
 	return false
 }
 
-func unauthorizedError(ctx *fiber.Ctx, err interface{}) bool {
-	exception, ok := err.(*UnauthorizedErrorStruct)
-	if ok {
-		ctx.Status(fiber.StatusUnauthorized).JSON(dto.Error{
-			Code:    fiber.StatusUnauthorized,
-			Status:  "UNAUTHORIZED",
-			Errors:  exception.Error(),
-			TraceID: ctx.Locals("requestid").(string),
+func internalServerError(ctx *fiber.Ctx, err error) bool {
+	var exception *InternalServerErrorStruct
+	if errors.As(err, &exception) {
+		render(ctx, failure{
+			status: fiber.StatusInternalServerError,
+			code:   orDefault(exception.Code, CodeInternal),
+			detail: exception.Error(),
 		})
 		return true
 	}
 	return false
 }
 
-func internalServerError(ctx *fiber.Ctx, err interface{}) bool {
-	exception, ok := err.(*InternalServerErrorStruct)
-	if ok {
-		ctx.Status(fiber.StatusInternalServerError).JSON(dto.Error{
-			Code:    fiber.StatusInternalServerError,
-			Status:  "INTERNAL SERVER ERROR",
-			Errors:  exception.Error(),
-			TraceID: ctx.Locals("requestid").(string),
+func fiberError(ctx *fiber.Ctx, err error) bool {
+	var exception *fiber.Error
+	if errors.As(err, &exception) {
+		render(ctx, failure{
+			status: exception.Code,
+			code:   statusCode(exception.Code),
+			detail: exception.Message,
 		})
 		return true
 	}
 	return false
+}
+
+// sentinelError handles errors that wrap a sentinel without one of the typed
+// errors, such as fmt.Errorf("customer %d: %w", id, ErrNotFound).
+func sentinelError(ctx *fiber.Ctx, err error) bool {
+	var f failure
+	switch {
+	case errors.Is(err, ErrNotFound):
+		f = failure{status: fiber.StatusNotFound, code: CodeNotFound}
+	case errors.Is(err, ErrConflict):
+		f = failure{status: fiber.StatusConflict, code: CodeConflict}
+	case errors.Is(err, ErrValidation):
+		f = failure{status: fiber.StatusBadRequest, code: CodeValidationFailed}
+	default:
+		return false
+	}
+	f.detail = err.Error()
+	render(ctx, f)
+	return true
+}
+
+func unknownError(ctx *fiber.Ctx, err error) {
+	slog.ErrorContext(ctx.Context(), "unhandled error", slog.String("error", err.Error()))
+	render(ctx, failure{
+		status: fiber.StatusInternalServerError,
+		code:   CodeInternal,
+		detail: "internal server error",
+	})
+}
+
+// NotFound answers requests no route matched.
+func NotFound(ctx *fiber.Ctx) error {
+	render(ctx, failure{
+		status: fiber.StatusNotFound,
+		code:   CodeRouteNotFound,
+		detail: "Page Not Found",
+	})
+	return nil
+}
+
+// failure is an error resolved to its response, before content negotiation.
+type failure struct {
+	status int
+	code   Code
+	detail string
+	// legacy replaces detail as the "errors" member of dto.Error.
+	legacy interface{}
+	fields []dto.FieldError
+}
+
+// render writes f as application/problem+json when the client prefers it over
+// application/json in Accept, and as dto.Error otherwise, so clients that
+// never asked for problems keep the old body.
+func render(ctx *fiber.Ctx, f failure) {
+	traceId, _ := ctx.Locals(utils.RequestIdKey).(string)
+	ctx.Vary(fiber.HeaderAccept)
+	ctx.Status(f.status)
+
+	if ctx.Accepts(fiber.MIMEApplicationJSON, MIMEProblemJSON) == MIMEProblemJSON {
+		title, ok := titles[f.code]
+		if !ok {
+			title = fiberutils.StatusMessage(f.status)
+		}
+		ctx.JSON(dto.Problem{
+			Type:     problemType(f.code),
+			Title:    title,
+			Status:   f.status,
+			Detail:   f.detail,
+			Instance: ctx.Path(),
+			Code:     string(f.code),
+			Errors:   f.fields,
+			TraceID:  traceId,
+		}, MIMEProblemJSON)
+		return
+	}
+
+	legacy := f.legacy
+	if legacy == nil {
+		legacy = f.detail
+	}
+	ctx.JSON(dto.Error{
+		Code:    f.status,
+		Status:  strings.ToUpper(fiberutils.StatusMessage(f.status)),
+		Errors:  legacy,
+		TraceID: traceId,
+	})
+}
+
+func orDefault(code Code, fallback Code) Code {
+	if code == "" {
+		return fallback
+	}
+	return code
+}
 
+// statusCode is the generic code of an HTTP status, e.g. TOO_MANY_REQUESTS.
+func statusCode(status int) Code {
+	return Code(strings.ToUpper(strings.ReplaceAll(fiberutils.StatusMessage(status), " ", "_")))
 }
@@ -2,6 +2,7 @@ package exception
 
 type InternalServerErrorStruct struct {
 	ErrorMsg string
+	Code     Code
 }
 
 func NewInternalServerErrorHandler(msg string) *InternalServerErrorStruct {
@@ -13,3 +14,8 @@ func NewInternalServerErrorHandler(msg string) *InternalServerErrorStruct {
 func (e *InternalServerErrorStruct) Error() string {
 	return e.ErrorMsg
 }
+
+func (e *InternalServerErrorStruct) WithCode(code Code) *InternalServerErrorStruct {
+	e.Code = code
+	return e
+}
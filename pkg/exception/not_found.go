@@ -2,6 +2,8 @@ package exception
 
 type NotFoundErrorStruct struct {
 	ErrorMsg string
+	Code     Code
+	Err      error
 }
 
 func NewNotFoundHandler(msg string) *NotFoundErrorStruct {
@@ -13,3 +15,16 @@ func NewNotFoundHandler(msg string) *NotFoundErrorStruct {
 func (e *NotFoundErrorStruct) Error() string {
 	return e.ErrorMsg
 }
+
+func (e *NotFoundErrorStruct) Unwrap() error {
+	return e.Err
+}
+
+func (e *NotFoundErrorStruct) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *NotFoundErrorStruct) WithCode(code Code) *NotFoundErrorStruct {
+	e.Code = code
+	return e
+}
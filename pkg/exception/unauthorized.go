@@ -2,6 +2,7 @@ package exception
 
 type UnauthorizedErrorStruct struct {
 	ErrorMsg string
+	Code     Code
 }
 
 func NewUnauthorizedHandler(msg string) *UnauthorizedErrorStruct {
@@ -13,3 +14,8 @@ func NewUnauthorizedHandler(msg string) *UnauthorizedErrorStruct {
 func (e *UnauthorizedErrorStruct) Error() string {
 	return e.ErrorMsg
 }
+
+func (e *UnauthorizedErrorStruct) WithCode(code Code) *UnauthorizedErrorStruct {
+	e.Code = code
+	return e
+}
@@ -0,0 +1,122 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+var ErrShuttingDown = errors.New("shutting down")
+
+// Check reports whether a dependency is usable. It should respect ctx, which
+// carries the per-check timeout.
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Status  string
+	Latency time.Duration
+	Err     error
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Registry holds the readiness checks registered by components.
+type Registry struct {
+	timeout  time.Duration
+	stopping func() bool
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewRegistry returns a Registry that runs every check with timeout and
+// reports not ready as soon as stopping returns true.
+func NewRegistry(timeout time.Duration, stopping func() bool) *Registry {
+	return &Registry{timeout: timeout, stopping: stopping}
+}
+
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// Ready runs every check concurrently and reports whether all of them passed
+// together with the result of each one.
+func (r *Registry) Ready(ctx context.Context) (bool, map[string]Result) {
+	r.mu.RLock()
+	checks := append([]namedCheck{}, r.checks...)
+	r.mu.RUnlock()
+
+	results := make(map[string]Result, len(checks)+1)
+	ready := true
+	if r.stopping != nil && r.stopping() {
+		ready = false
+		results["lifecycle"] = Result{Status: StatusDown, Err: ErrShuttingDown}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+			result := r.run(ctx, c.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[c.name] = result
+			if result.Err != nil {
+				ready = false
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return ready, results
+}
+
+func (r *Registry) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := Result{Status: StatusUp, Latency: time.Since(start), Err: err}
+	if err != nil {
+		result.Status = StatusDown
+	}
+	return result
+}
+
+// HTTP checks that url answers with a status below 500. Any answer proves the
+// upstream is reachable, even one rejecting the unauthenticated probe.
+func HTTP(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("responded with status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
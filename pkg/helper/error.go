@@ -4,4 +4,4 @@ func ErrorPanic(err error) {
 	if err != nil {
 		panic(err)
 	}
-}
\ No newline at end of file
+}
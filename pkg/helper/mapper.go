@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 )
 
+// Automapper copies objOrigin into objDestination through JSON, dropping
+// fields whose JSON shapes differ and ignoring errors.
+//
+// Deprecated: use the generated mappers in dto (go generate ./dto); this is
+// kept as the baseline of BenchmarkCustomerMapping.
 func Automapper(objOrigin interface{}, objDestination interface{}) {
 	jsonOrigin := StructToJson(objOrigin)
 	json.Unmarshal([]byte(jsonOrigin), objDestination)
@@ -0,0 +1,106 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// Hook releases a resource during shutdown. It should return once the resource
+// is closed or ctx is done, whichever comes first.
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	fn   Hook
+}
+
+// Lifecycle tracks background workers and the cleanup hooks of long-lived
+// components so the process can stop in order.
+type Lifecycle struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stopping atomic.Bool
+
+	mu    sync.Mutex
+	hooks []namedHook
+}
+
+func New() *Lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// Context is cancelled as soon as shutdown begins. Workers started outside Go
+// should stop when it is done.
+func (l *Lifecycle) Context() context.Context {
+	return l.ctx
+}
+
+// Stopping reports whether shutdown has begun.
+func (l *Lifecycle) Stopping() bool {
+	return l.stopping.Load()
+}
+
+// Go runs a background worker with the lifecycle context and registers a hook
+// that waits for it to return.
+func (l *Lifecycle) Go(name string, fn func(ctx context.Context)) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(l.ctx)
+	}()
+	l.OnStop(name, func(ctx context.Context) error {
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// OnStop registers a hook run during shutdown. Hooks run in reverse
+// registration order, so a component registered after its dependencies is
+// closed before them.
+func (l *Lifecycle) OnStop(name string, fn Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, namedHook{name: name, fn: fn})
+}
+
+// Signals returns a channel receiving SIGINT and SIGTERM.
+func Signals() <-chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	return signals
+}
+
+// Shutdown cancels the lifecycle context and runs every hook in reverse
+// registration order, bounded by ctx. It returns the errors of the hooks that
+// failed; a hook still running when ctx is done reports ctx.Err().
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.stopping.Store(true)
+	l.cancel()
+
+	l.mu.Lock()
+	hooks := append([]namedHook{}, l.hooks...)
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+			continue
+		}
+		slog.Info("stopped", slog.String("component", hooks[i].name))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"scylla/pkg/config"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var gormLevels = map[string]gormlogger.LogLevel{
+	"silent": gormlogger.Silent,
+	"error":  gormlogger.Error,
+	"warn":   gormlogger.Warn,
+	"info":   gormlogger.Info,
+}
+
+type gormSettings struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// GormLogger writes GORM logs to slog. database.log_level decides what GORM
+// reports: errors, slow queries or every statement. Statements are logged
+// with placeholders, never with their parameters.
+type GormLogger struct {
+	log      *slog.Logger
+	settings atomic.Pointer[gormSettings]
+}
+
+func NewGormLogger(log *slog.Logger, conf config.Database) *GormLogger {
+	gormLogger := &GormLogger{log: log.With(slog.String("component", "gorm"))}
+	gormLogger.Configure(conf)
+	return gormLogger
+}
+
+// Configure applies the log level and slow threshold of conf.
+func (l *GormLogger) Configure(conf config.Database) {
+	l.settings.Store(&gormSettings{level: gormLevels[conf.LogLevel], slowThreshold: conf.SlowThreshold})
+}
+
+// LogMode returns a copy fixed at level, as used by db.Debug().
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	settings := *l.settings.Load()
+	settings.level = level
+	copied := &GormLogger{log: l.log}
+	copied.settings.Store(&settings)
+	return copied
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.settings.Load().level >= gormlogger.Info {
+		l.log.InfoContext(ctx, msg, slog.Any("data", data))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.settings.Load().level >= gormlogger.Warn {
+		l.log.WarnContext(ctx, msg, slog.Any("data", data))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.settings.Load().level >= gormlogger.Error {
+		l.log.ErrorContext(ctx, msg, slog.Any("data", data))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	settings := l.settings.Load()
+	if settings.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	attrs := func() []slog.Attr {
+		sql, rows := fc()
+		return []slog.Attr{
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Float64("elapsed_ms", float64(elapsed)/float64(time.Millisecond)),
+		}
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && settings.level >= gormlogger.Error:
+		l.log.LogAttrs(ctx, slog.LevelError, "query failed", append(attrs(), slog.String("error", err.Error()))...)
+	case settings.slowThreshold > 0 && elapsed > settings.slowThreshold && settings.level >= gormlogger.Warn:
+		l.log.LogAttrs(ctx, slog.LevelWarn, "slow query", append(attrs(), slog.Duration("threshold", settings.slowThreshold))...)
+	case settings.level >= gormlogger.Info:
+		l.log.LogAttrs(ctx, slog.LevelInfo, "query", attrs()...)
+	}
+}
+
+// ParamsFilter drops the statement parameters so values such as emails and
+// phone numbers never reach the log.
+func (l *GormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
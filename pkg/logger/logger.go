@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"scylla/pkg/config"
+	"scylla/pkg/utils"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are matched case-insensitively against every attribute key,
+// including the fields of structs and maps logged as a single value.
+var sensitiveKeys = []string{"email", "phone", "token", "password", "secret", "authorization", "cookie"}
+
+// errorPatterns find values quoted in error messages, such as the key of a
+// unique violation detail or the input of a cast, and any email address or
+// phone number left in them.
+var errorPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(Key \([^)]*\)=\()[^)]*(\))`),
+	regexp.MustCompile(`(for type \w+: ")[^"]*(")`),
+	regexp.MustCompile(`()[^\s@"'()<>]+@[^\s@"'()<>]+()`),
+	regexp.MustCompile(`()\+?\d(?:[- ]?\d){8,}()`),
+}
+
+var level = new(slog.LevelVar)
+
+// New returns a JSON logger writing to w at the configured level, adding the
+// request id, user id and tenant of the context to every record logged with a
+// *Context method and redacting sensitive fields and the values errors
+// carry.
+func New(conf config.Log, w io.Writer) *slog.Logger {
+	level.Set(parseLevel(conf.Level))
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})
+	return slog.New(contextHandler{Handler: handler})
+}
+
+// Setup builds the logger, installs it as the slog default and applies
+// log.level changes made by a config reload.
+func Setup(conf config.Log, w io.Writer) *slog.Logger {
+	log := New(conf, w)
+	slog.SetDefault(log)
+	config.Subscribe(func(old, new *config.Config) {
+		if old.Log.Level != new.Log.Level {
+			level.Set(parseLevel(new.Log.Level))
+		}
+	})
+	return log
+}
+
+func parseLevel(name string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestId := utils.GetTraceId(ctx); requestId != "" {
+			record.AddAttrs(slog.String("request_id", requestId))
+		}
+		if userId, ok := utils.GetUserId(ctx); ok {
+			record.AddAttrs(slog.String("user_id", userId))
+		}
+		if tenantId, ok := utils.GetTenantId(ctx); ok {
+			record.AddAttrs(slog.String("tenant_id", tenantId))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	if isSensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	// errors from the database quote the offending values, so "error"
+	// attributes keep only their message shape
+	if attr.Key == "error" && attr.Value.Kind() == slog.KindString {
+		return slog.String(attr.Key, redactError(attr.Value.String()))
+	}
+	if attr.Value.Kind() == slog.KindAny {
+		attr.Value = redactValue(attr.Value.Any())
+	}
+	return attr
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue reaches into structs, maps and slices by round-tripping them
+// through JSON, so the keys checked are the ones that end up in the log.
+func redactValue(value any) slog.Value {
+	if err, ok := value.(error); ok {
+		return slog.StringValue(redactError(err.Error()))
+	}
+	// errors marshal to {}, so lists such as GORM's log data are walked here
+	if values, ok := value.([]any); ok {
+		redactedValues := make([]any, len(values))
+		for i, nested := range values {
+			redactedValues[i] = redactValue(nested).Any()
+		}
+		return slog.AnyValue(redactedValues)
+	}
+	kind := reflect.Indirect(reflect.ValueOf(value)).Kind()
+	if kind != reflect.Struct && kind != reflect.Map && kind != reflect.Slice && kind != reflect.Array {
+		return slog.AnyValue(value)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return slog.AnyValue(value)
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return slog.AnyValue(value)
+	}
+	return slog.AnyValue(redactDecoded(decoded))
+}
+
+// redactError blanks the values quoted in an error message.
+func redactError(msg string) string {
+	for _, pattern := range errorPatterns {
+		msg = pattern.ReplaceAllString(msg, "${1}"+redacted+"${2}")
+	}
+	return msg
+}
+
+func redactDecoded(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			if isSensitive(key) {
+				value[key] = redacted
+				continue
+			}
+			value[key] = redactDecoded(nested)
+		}
+	case []any:
+		for i, nested := range value {
+			value[i] = redactDecoded(nested)
+		}
+	}
+	return value
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware writes one access log record per request with the route
+// template, status and latency. Errors are rendered here, like Fiber's own
+// logger does, so the status logged is the one the client receives.
+func Middleware(log *slog.Logger) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+		if err := ctx.Next(); err != nil {
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := ctx.Response().StatusCode()
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		log.LogAttrs(ctx.Context(), level, "request",
+			slog.String("method", ctx.Method()),
+			slog.String("route", ctx.Route().Path),
+			slog.String("path", ctx.Path()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start))/float64(time.Millisecond)),
+			slog.String("ip", ctx.IP()),
+		)
+		return nil
+	}
+}
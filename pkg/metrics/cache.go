@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"scylla/pkg/cache"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterCache exposes the counters of a cache as
+// scylla_cache_<counter>_total{cache="name"}.
+func RegisterCache(name string, metrics *cache.Metrics) {
+	for counter := range metrics.Snapshot() {
+		counter := counter
+		factory.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "cache_" + counter + "_total",
+			Help:        "Cache " + counter + " counter.",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}, func() float64 {
+			return float64(metrics.Snapshot()[counter])
+		})
+	}
+}
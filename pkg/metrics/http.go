@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels requests no route handled, so scanners probing random
+// paths cannot blow up the label cardinality.
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+	httpRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// HTTP records every request under the template of the route that handled it,
+// such as /api/v1/customers/:customerId, never the raw path. It runs inside
+// recover, so a panicking handler is recorded as the 500 recover answers with
+// before the panic is passed on.
+func HTTP() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				observe(ctx, start, fiber.StatusInternalServerError)
+				panic(r)
+			}
+		}()
+
+		// like the logger middleware, render the error here so the status
+		// recorded is the one the client receives
+		if err := ctx.Next(); err != nil {
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		observe(ctx, start, ctx.Response().StatusCode())
+		return nil
+	}
+}
+
+func observe(ctx *fiber.Ctx, start time.Time, status int) {
+	// after Next, Route is the last route that ran; the catch-all not-found
+	// middleware is mounted at "/"
+	route := ctx.Route().Path
+	if route == "/" && ctx.Path() != "/" {
+		route = unmatchedRoute
+	}
+
+	labels := prometheus.Labels{
+		"method": ctx.Method(),
+		"route":  route,
+		"status": strconv.Itoa(status),
+	}
+	httpRequests.With(labels).Inc()
+	httpRequestDuration.With(labels).Observe(time.Since(start).Seconds())
+}
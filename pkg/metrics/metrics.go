@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "scylla"
+
+// Registry holds every metric exposed on /metrics. A dedicated registry keeps
+// metrics registered by dependencies on the default one out of the output.
+var Registry = func() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}()
+
+var factory = promauto.With(Registry)
+
+var (
+	DmsRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dms_requests_total",
+		Help:      "Calls to the DMS upstream by operation and outcome.",
+	}, []string{"operation", "outcome"})
+	DmsRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dms_request_duration_seconds",
+		Help:      "Latency of calls to the DMS upstream by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	ImportJobs = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "import_jobs_total",
+		Help:      "Import jobs by kind and result.",
+	}, []string{"kind", "result"})
+	ImportRows = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "import_rows_total",
+		Help:      "Imported rows by kind and result (processed or failed).",
+	}, []string{"kind", "result"})
+)
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+}
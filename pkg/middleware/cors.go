@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"scylla/pkg/config"
+)
+
+// Cors allows the origins listed in runtime.cors_origins, read on every
+// request so a config reload applies immediately. "*" allows any origin.
+func Cors() fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOriginsFunc: func(origin string) bool {
+			for _, allowed := range config.Get().Runtime.CorsOrigins {
+				if allowed == "*" || allowed == origin {
+					return true
+				}
+			}
+			return false
+		},
+	})
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"scylla/pkg/utils"
+)
+
+// Database stores db in ctx.Locals so code holding only the request context,
+// such as the unique and exists validators, reads it with utils.GetDB.
+func Database(db *gorm.DB) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		ctx.Locals(utils.DBKey, db)
+		return ctx.Next()
+	}
+}
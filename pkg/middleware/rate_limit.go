@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"scylla/pkg/config"
+	"sync/atomic"
+)
+
+// RateLimit limits requests per client IP using runtime.rate_limit. The
+// limiter is rebuilt when a reload changes the limits, which resets the
+// current windows. A max of 0 disables it.
+func RateLimit() fiber.Handler {
+	var current atomic.Pointer[fiber.Handler]
+	build := func(conf config.RateLimit) {
+		handler := func(ctx *fiber.Ctx) error {
+			return ctx.Next()
+		}
+		if conf.Max > 0 {
+			handler = limiter.New(limiter.Config{
+				Max:        conf.Max,
+				Expiration: conf.Window,
+			})
+		}
+		current.Store(&handler)
+	}
+
+	build(config.Get().Runtime.RateLimit)
+	config.Subscribe(func(old, new *config.Config) {
+		if old.Runtime.RateLimit != new.Runtime.RateLimit {
+			build(new.Runtime.RateLimit)
+		}
+	})
+
+	return func(ctx *fiber.Ctx) error {
+		return (*current.Load())(ctx)
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"fmt"
+	"net/netip"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const TenantHeader = "X-Tenant-ID"
+
+// Principal is implemented by the authenticated caller stored in
+// ctx.Locals("user") by the auth middleware.
+type Principal interface {
+	TenantID() string
+}
+
+// Tenant resolves the DMS customer id of the request and stores it in
+// ctx.Locals so services can read it with utils.GetTenantId. The authenticated
+// principal wins over the X-Tenant-ID header, and the configured default is
+// used only when neither is present. The header is only believed from the
+// gateways in conf.TrustedProxies, matched on the connection address rather
+// than forwarded headers; from anyone else it is rejected, so clients cannot
+// pick another tenant. Requests without a tenant are not rejected here;
+// services that need one refuse them instead.
+func Tenant(conf config.Kong) fiber.Handler {
+	trusted := trustedPrefixes(conf.TrustedProxies)
+	return func(ctx *fiber.Ctx) error {
+		header := strings.TrimSpace(ctx.Get(TenantHeader))
+		if header != "" && !isTrusted(trusted, ctx.Context().RemoteIP().String()) {
+			return exception.NewUnauthorizedHandler(fmt.Sprintf("%s is only accepted from a trusted gateway", TenantHeader)).WithCode(exception.CodeUntrustedTenant)
+		}
+
+		tenantId := ""
+		if principal, ok := ctx.Locals(utils.UserKey).(Principal); ok {
+			tenantId = principal.TenantID()
+		}
+		if tenantId == "" {
+			tenantId = header
+		}
+		if tenantId == "" {
+			tenantId = conf.DefaultCustId
+		}
+		if tenantId != "" {
+			ctx.Locals(utils.TenantIdKey, tenantId)
+		}
+		return ctx.Next()
+	}
+}
+
+// trustedPrefixes parses IPs and CIDRs; the config validates them, so
+// anything unparsable is skipped rather than trusted.
+func trustedPrefixes(proxies []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(proxy); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return prefixes
+}
+
+func isTrusted(prefixes []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"fmt"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const TimezoneHeader = "X-Timezone"
+
+// Timezone stores the timezone of the request in ctx.Locals for
+// utils.GetLocation: the X-Timezone header, an IANA name such as Asia/Jakarta
+// or an offset such as +07:00, or defaultLocation without it. Dates without an
+// offset are read and response timestamps rendered in it.
+func Timezone(defaultLocation *time.Location) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		ctx.Vary(TimezoneHeader)
+
+		loc := defaultLocation
+		if name := strings.TrimSpace(ctx.Get(TimezoneHeader)); name != "" {
+			var err error
+			if loc, err = utils.LoadLocation(name); err != nil {
+				return exception.NewBadRequestHandler(fmt.Sprintf("%s %q is not an IANA timezone such as Asia/Jakarta or an offset such as +07:00", TimezoneHeader, name)).WithCode(exception.CodeInvalidTimezone)
+			}
+		}
+		ctx.Locals(utils.TimezoneKey, loc)
+		return ctx.Next()
+	}
+}
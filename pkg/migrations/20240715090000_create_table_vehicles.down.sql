@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vehicle_sync_runs;
+DROP TABLE IF EXISTS vehicles;
\ No newline at end of file
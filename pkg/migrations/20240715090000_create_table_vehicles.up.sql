@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS vehicles (
+    id SERIAL PRIMARY KEY,
+    cust_id VARCHAR(50) NOT NULL,
+    vehicle_id BIGINT NOT NULL,
+    vehicle_no VARCHAR(50) NULL,
+    vehicle_desc VARCHAR(255) NULL,
+    vehicle_type_name VARCHAR(125) NULL,
+    length DOUBLE PRECISION NULL,
+    width DOUBLE PRECISION NULL,
+    height DOUBLE PRECISION NULL,
+    volume DOUBLE PRECISION NULL,
+    driver_id BIGINT NULL,
+    helper_id BIGINT NULL,
+    driver_name VARCHAR(125) NULL,
+    helper_name VARCHAR(125) NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    synced_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    updated_at timestamptz NULL
+);
+
+ALTER TABLE vehicles
+ADD CONSTRAINT unique_vehicle_cust UNIQUE (cust_id, vehicle_id);
+
+CREATE TABLE IF NOT EXISTS vehicle_sync_runs (
+    id SERIAL PRIMARY KEY,
+    cust_id VARCHAR(50) NOT NULL,
+    trigger VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    pages INT NOT NULL DEFAULT 0,
+    fetched INT NOT NULL DEFAULT 0,
+    upserted INT NOT NULL DEFAULT 0,
+    deactivated INT NOT NULL DEFAULT 0,
+    error TEXT NULL,
+    started_at timestamptz NOT NULL DEFAULT (now()),
+    finished_at timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_sync_runs_cust_started ON vehicle_sync_runs (cust_id, started_at DESC);
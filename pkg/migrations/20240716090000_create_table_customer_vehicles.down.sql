@@ -0,0 +1 @@
+DROP TABLE IF EXISTS customer_vehicles;
\ No newline at end of file
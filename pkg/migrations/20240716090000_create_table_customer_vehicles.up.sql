@@ -0,0 +1,23 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS customer_vehicles (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    cust_id VARCHAR(50) NOT NULL,
+    vehicle_id BIGINT NOT NULL,
+    effective_from DATE NOT NULL,
+    effective_to DATE NULL,
+    created_at timestamptz NOT NULL DEFAULT (now()),
+    updated_at timestamptz NULL,
+    CONSTRAINT check_effective_range CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_vehicles_customer ON customer_vehicles (customer_id);
+
+-- a vehicle can serve only one customer on any given day
+ALTER TABLE customer_vehicles
+ADD CONSTRAINT exclude_customer_vehicles_overlap EXCLUDE USING gist (
+    cust_id WITH =,
+    vehicle_id WITH =,
+    daterange(effective_from, effective_to, '[]') WITH &&
+);
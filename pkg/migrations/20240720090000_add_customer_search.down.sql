@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS customers_search_vector_update ON customers;
+DROP FUNCTION IF EXISTS customers_search_vector_update();
+
+ALTER TABLE customers DROP COLUMN IF EXISTS search_vector;
+
+DROP FUNCTION IF EXISTS customer_search_document(text, text, text, text);
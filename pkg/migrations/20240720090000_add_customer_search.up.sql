@@ -0,0 +1,30 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS fuzzystrmatch;
+
+-- full-text document for GET /customers/search, names weighted above address
+CREATE OR REPLACE FUNCTION customer_search_document(username text, email text, phone text, address text)
+RETURNS tsvector
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(username, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(email, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(phone, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(address, '')), 'C')
+$$;
+
+-- a nullable column without a default is added without rewriting the table;
+-- the trigger fills it for new rows and the next migration for existing ones
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION customers_search_vector_update()
+RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.search_vector := customer_search_document(NEW.username, NEW.email, NEW.phone, NEW.address);
+    RETURN NEW;
+END
+$$;
+
+DROP TRIGGER IF EXISTS customers_search_vector_update ON customers;
+CREATE TRIGGER customers_search_vector_update
+BEFORE INSERT OR UPDATE OF username, email, phone, address ON customers
+FOR EACH ROW EXECUTE FUNCTION customers_search_vector_update();
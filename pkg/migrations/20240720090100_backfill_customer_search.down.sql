@@ -0,0 +1 @@
+-- nothing to undo, the column is dropped by the add_customer_search down migration
@@ -0,0 +1,20 @@
+-- fills search_vector of the rows that predate the trigger in batches, each
+-- committed on its own so locks are short and progress survives a failure.
+-- This file must stay a single statement: golang-migrate then runs it outside
+-- a transaction block, which COMMIT inside DO requires.
+DO $$
+DECLARE
+    batch_size CONSTANT integer := 10000;
+    batch_start integer;
+    last_id integer;
+BEGIN
+    SELECT coalesce(min(id), 0), coalesce(max(id), 0) INTO batch_start, last_id FROM customers;
+    WHILE batch_start <= last_id LOOP
+        UPDATE customers
+        SET search_vector = customer_search_document(username, email, phone, address)
+        WHERE id >= batch_start AND id < batch_start + batch_size AND search_vector IS NULL;
+        COMMIT;
+        batch_start := batch_start + batch_size;
+    END LOOP;
+END
+$$;
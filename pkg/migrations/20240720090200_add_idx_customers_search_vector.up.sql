@@ -0,0 +1,3 @@
+-- CONCURRENTLY keeps customers writable while the index builds. It cannot run
+-- in a transaction, so each index has a migration of its own statement.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_customers_search_vector ON customers USING gin (search_vector);
@@ -0,0 +1,2 @@
+-- trigram indexes serve fuzzy matches as well as the ILIKE filters
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_customers_username_trgm ON customers USING gin (username gin_trgm_ops);
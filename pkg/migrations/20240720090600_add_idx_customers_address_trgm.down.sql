@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS idx_customers_address_trgm;
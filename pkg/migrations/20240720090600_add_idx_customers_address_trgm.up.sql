@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_customers_address_trgm ON customers USING gin (address gin_trgm_ops);
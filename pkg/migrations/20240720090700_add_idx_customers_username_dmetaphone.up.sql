@@ -0,0 +1,3 @@
+-- short names share too few trigrams for typos such as Jhon/John, so they
+-- are also matched by how they sound
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_customers_username_dmetaphone ON customers (dmetaphone(username));
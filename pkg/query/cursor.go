@@ -0,0 +1,156 @@
+package query
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"scylla/pkg/exception"
+)
+
+// Cursor points just past a row of a keyset-paginated list. Values are the
+// sort key of that row, in the order of the sorts it was made for.
+type Cursor struct {
+	Sort     string        `json:"s"`
+	Values   []interface{} `json:"v"`
+	Backward bool          `json:"b,omitempty"`
+}
+
+// Cursors signs cursors so clients cannot forge sort keys, and rejects cursors
+// made for another sort.
+type Cursors struct {
+	key []byte
+}
+
+// NewCursors signs with secret, which must be the same on every instance for
+// cursors to survive restarts and load balancing.
+func NewCursors(secret string) *Cursors {
+	return &Cursors{key: []byte(secret)}
+}
+
+// Encode returns the opaque form of a cursor positioned at values.
+func (c *Cursors) Encode(sorts []Sort, values []interface{}, backward bool) string {
+	payload, _ := json.Marshal(Cursor{Sort: SortKey(sorts), Values: values, Backward: backward})
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// Decode verifies raw and checks it was made for sorts.
+func (c *Cursors) Decode(raw string, sorts []Sort) (Cursor, error) {
+	var cursor Cursor
+	encoded, signature, ok := strings.Cut(raw, ".")
+	if !ok {
+		return cursor, invalidCursor("cursor is malformed")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, invalidCursor("cursor is malformed")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return cursor, invalidCursor("cursor signature is invalid")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&cursor); err != nil {
+		return cursor, invalidCursor("cursor is malformed")
+	}
+	if cursor.Sort != SortKey(sorts) || len(cursor.Values) != len(sorts) {
+		return cursor, invalidCursor(fmt.Sprintf("cursor was made for sort %q, not %q", cursor.Sort, SortKey(sorts)))
+	}
+	// keep integer keys integers rather than the float64 json decodes to
+	for i, value := range cursor.Values {
+		if number, ok := value.(json.Number); ok {
+			if n, err := number.Int64(); err == nil {
+				cursor.Values[i] = n
+			} else {
+				cursor.Values[i] = number.String()
+			}
+		}
+	}
+	return cursor, nil
+}
+
+func (c *Cursors) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// SortKey is the canonical form of sorts, e.g. "created_at:desc,id:desc".
+func SortKey(sorts []Sort) string {
+	parts := make([]string, len(sorts))
+	for i, s := range sorts {
+		direction := "asc"
+		if s.Desc {
+			direction = "desc"
+		}
+		parts[i] = s.Field + ":" + direction
+	}
+	return strings.Join(parts, ",")
+}
+
+// WithTiebreaker appends a sort on the unique column unless sorts already
+// include it, so every row has a distinct key. It takes the direction of the
+// last sort.
+func WithTiebreaker(sorts []Sort, field, column string) []Sort {
+	for _, s := range sorts {
+		if s.Column == column {
+			return sorts
+		}
+	}
+	tiebreaker := Sort{Field: field, Column: column}
+	if len(sorts) > 0 {
+		tiebreaker.Desc = sorts[len(sorts)-1].Desc
+	}
+	return append(append([]Sort{}, sorts...), tiebreaker)
+}
+
+// Reverse flips the direction of every sort, for reading a page backwards.
+func Reverse(sorts []Sort) []Sort {
+	reversed := make([]Sort, len(sorts))
+	for i, s := range sorts {
+		s.Desc = !s.Desc
+		reversed[i] = s
+	}
+	return reversed
+}
+
+// Keyset selects the rows after values in the order of sorts:
+// (a > x) OR (a = x AND b > y) ..., with < for descending sorts. The columns
+// must not be nullable.
+func Keyset(sorts []Sort, values []interface{}) clause.Expression {
+	var or []string
+	var vars []interface{}
+	for i := range sorts {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, "? = ?")
+			vars = append(vars, clause.Column{Name: sorts[j].Column}, values[j])
+		}
+		op := ">"
+		if sorts[i].Desc {
+			op = "<"
+		}
+		and = append(and, "? "+op+" ?")
+		vars = append(vars, clause.Column{Name: sorts[i].Column}, values[i])
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return clause.Expr{SQL: "(" + strings.Join(or, " OR ") + ")", Vars: vars}
+}
+
+// Page is the position of a list page. Total is nil when it was not counted.
+type Page struct {
+	Total      *int64
+	NextCursor string
+	PrevCursor string
+}
+
+func invalidCursor(msg string) error {
+	return exception.NewBadRequestHandler(msg).WithCode(exception.CodeInvalidCursor)
+}
@@ -0,0 +1,158 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"scylla/pkg/exception"
+)
+
+// Fieldset is the JSON fields of a response a client asked for with
+// ?fields=. A nil Fieldset means every field.
+type Fieldset []string
+
+// ParseFieldset parses a comma separated list of fields against the JSON
+// fields of response, a struct. Fields tagged gorm:"-" are relations rather
+// than columns and are reported as to be loaded with include instead.
+func ParseFieldset(raw string, response interface{}) (Fieldset, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	fields := jsonFieldsOf(reflect.TypeOf(response))
+	var fieldset Fieldset
+	seen := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		field, ok := fields.byName[name]
+		if !ok {
+			return nil, invalidFields(fmt.Sprintf("unknown field %q, allowed fields: %s", name, strings.Join(fields.columns, ", ")))
+		}
+		if field.relation {
+			return nil, invalidFields(fmt.Sprintf("%q is a relation, request it with include=%s", name, name))
+		}
+		if !seen[name] {
+			seen[name] = true
+			fieldset = append(fieldset, name)
+		}
+	}
+	return fieldset, nil
+}
+
+// Columns returns the columns to select for the fieldset: the ones the asked
+// fields map to in columns, followed by required ones such as the primary key
+// and sort columns the query itself needs.
+func (f Fieldset) Columns(columns Fields, required ...string) []string {
+	names := f
+	if names == nil {
+		names = columns.Names()
+	}
+	var selected []string
+	seen := map[string]bool{}
+	add := func(column string) {
+		if column != "" && !seen[column] {
+			seen[column] = true
+			selected = append(selected, column)
+		}
+	}
+	for _, name := range names {
+		add(columns[name])
+	}
+	for _, column := range required {
+		add(column)
+	}
+	return selected
+}
+
+// Project returns the asked fields of v, a struct, keyed by their JSON names,
+// together with the fields named in keep such as included relations.
+func (f Fieldset) Project(v interface{}, keep ...string) map[string]interface{} {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	fields := jsonFieldsOf(value.Type())
+	projected := make(map[string]interface{}, len(f)+len(keep))
+	for _, name := range append(append([]string{}, f...), keep...) {
+		if field, ok := fields.byName[name]; ok {
+			projected[name] = value.FieldByIndex(field.index).Interface()
+		}
+	}
+	return projected
+}
+
+// ParseInclude parses a comma separated list of relations to embed against
+// the ones a resource can load.
+func ParseInclude(raw string, allowed ...string) ([]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var includes []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(allowed, name) {
+			return nil, exception.NewBadRequestHandler(fmt.Sprintf("cannot include %q, allowed relations: %s", name, strings.Join(allowed, ", "))).WithCode(exception.CodeInvalidInclude)
+		}
+		if !seen[name] {
+			seen[name] = true
+			includes = append(includes, name)
+		}
+	}
+	return includes, nil
+}
+
+type jsonField struct {
+	index    []int
+	relation bool
+}
+
+type jsonFields struct {
+	byName map[string]jsonField
+	// columns lists the names that are not relations, sorted, for errors.
+	columns []string
+}
+
+var jsonFieldsCache sync.Map
+
+// jsonFieldsOf indexes the exported fields of t, embedded ones included, by
+// their JSON names.
+func jsonFieldsOf(t reflect.Type) jsonFields {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if cached, ok := jsonFieldsCache.Load(t); ok {
+		return cached.(jsonFields)
+	}
+
+	fields := jsonFields{byName: map[string]jsonField{}}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		relation := field.Tag.Get("gorm") == "-"
+		fields.byName[name] = jsonField{index: field.Index, relation: relation}
+		if !relation {
+			fields.columns = append(fields.columns, name)
+		}
+	}
+	sort.Strings(fields.columns)
+
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+func invalidFields(msg string) error {
+	return exception.NewBadRequestHandler(msg).WithCode(exception.CodeInvalidFields)
+}
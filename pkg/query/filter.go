@@ -0,0 +1,289 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+)
+
+// Op compares a filtered column with the value the client sent.
+type Op string
+
+const (
+	OpEq     Op = "eq"
+	OpNe     Op = "ne"
+	OpIn     Op = "in"
+	OpLike   Op = "like"
+	OpPrefix Op = "prefix"
+	OpGte    Op = "gte"
+	OpLte    Op = "lte"
+	OpNull   Op = "null"
+)
+
+// Kind is the type of a filterable column. Values are parsed to it before
+// they reach the query, so a bad value is a 400 rather than a database error.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindTime
+)
+
+// ops lists the operators each kind supports, in the order error messages
+// show them.
+var ops = map[Kind][]Op{
+	KindString: {OpEq, OpNe, OpIn, OpLike, OpPrefix, OpNull},
+	KindInt:    {OpEq, OpNe, OpIn, OpGte, OpLte, OpNull},
+	KindTime:   {OpEq, OpNe, OpGte, OpLte, OpNull},
+}
+
+// maxInValues bounds the list of an in filter.
+const maxInValues = 100
+
+// FilterField is a column clients may filter on.
+type FilterField struct {
+	Column string
+	Kind   Kind
+}
+
+// FilterFields maps the API names of a resource to the columns they filter,
+// the filter counterpart of Fields.
+type FilterFields map[string]FilterField
+
+// Names returns the API names in alphabetical order.
+func (f FilterFields) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Filter is one parsed condition, e.g. filter[phone][prefix]=+62.
+type Filter struct {
+	Field  string
+	Column string
+	Op     Op
+	// Value is a string, int64, time.Time or, for a date, the day it covers;
+	// a slice of those for OpIn and a bool for OpNull.
+	Value interface{}
+}
+
+// day is a date filter value, the instants from its first up to the next
+// day's first in the request timezone.
+type day struct {
+	from, to time.Time
+}
+
+const filterPrefix = "filter["
+
+// FilterParams picks the filter[field][op] parameters out of a query string.
+func FilterParams(queries map[string]string) map[string]string {
+	params := map[string]string{}
+	for key, value := range queries {
+		if strings.HasPrefix(key, filterPrefix) {
+			params[key] = value
+		}
+	}
+	return params
+}
+
+// ParseFilter parses filter[field][op]=value parameters against fields. The
+// op defaults to eq. in takes a comma separated list, like matches anywhere
+// in the value and prefix at its start (both ignoring case), null takes true
+// or false, and times are anything utils.ParseDateTime accepts, read in loc
+// when they have no offset. A date covers its whole day, so lte includes it.
+// Unknown fields, operators or unparsable values are reported as a bad request
+// listing what is allowed.
+func ParseFilter(params map[string]string, fields FilterFields, loc *time.Location) ([]Filter, error) {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var filters []Filter
+	for _, key := range keys {
+		name, op, ok := filterKey(key)
+		if !ok {
+			return nil, invalidFilter(fmt.Sprintf("filter %q must be filter[field] or filter[field][op]", key))
+		}
+		field, ok := fields[name]
+		if !ok {
+			return nil, invalidFilter(fmt.Sprintf("cannot filter by %q, allowed fields: %s", name, strings.Join(fields.Names(), ", ")))
+		}
+		if !supports(field.Kind, op) {
+			return nil, invalidFilter(fmt.Sprintf("cannot filter %q with %q, allowed operators: %s", name, op, opNames(field.Kind)))
+		}
+
+		value, err := filterValue(field.Kind, op, params[key], loc)
+		if err != nil {
+			return nil, invalidFilter(fmt.Sprintf("filter[%s][%s]: %v", name, op, err))
+		}
+		filters = append(filters, Filter{Field: name, Column: field.Column, Op: op, Value: value})
+	}
+	return filters, nil
+}
+
+// TimeFilter is the filter ParseFilter builds for a time value already parsed
+// with utils.ParseDateTime.
+func TimeFilter(field, column string, op Op, value utils.DateTime) Filter {
+	return Filter{Field: field, Column: column, Op: op, Value: timeValue(value)}
+}
+
+// Has reports whether filters hold one on field with op.
+func Has(filters []Filter, field string, op Op) bool {
+	for _, filter := range filters {
+		if filter.Field == field && filter.Op == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Expression renders the filter with a quoted column and its value as a
+// parameter, for use with gorm's Where.
+func (f Filter) Expression() clause.Expression {
+	column := clause.Column{Name: f.Column}
+	if d, ok := f.Value.(day); ok {
+		return d.expression(column, f.Op)
+	}
+	switch f.Op {
+	case OpNe:
+		return clause.Neq{Column: column, Value: f.Value}
+	case OpIn:
+		return clause.IN{Column: column, Values: f.Value.([]interface{})}
+	case OpLike:
+		return clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{column, "%" + escapeLike(f.Value.(string)) + "%"}}
+	case OpPrefix:
+		return clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{column, escapeLike(f.Value.(string)) + "%"}}
+	case OpGte:
+		return clause.Gte{Column: column, Value: f.Value}
+	case OpLte:
+		return clause.Lte{Column: column, Value: f.Value}
+	case OpNull:
+		// a nil value renders IS NULL and IS NOT NULL
+		if f.Value.(bool) {
+			return clause.Eq{Column: column}
+		}
+		return clause.Neq{Column: column}
+	}
+	return clause.Eq{Column: column, Value: f.Value}
+}
+
+func (d day) expression(column clause.Column, op Op) clause.Expression {
+	switch op {
+	case OpNe:
+		return clause.Or(clause.Lt{Column: column, Value: d.from}, clause.Gte{Column: column, Value: d.to})
+	case OpGte:
+		return clause.Gte{Column: column, Value: d.from}
+	case OpLte:
+		return clause.Lt{Column: column, Value: d.to}
+	}
+	return clause.And(clause.Gte{Column: column, Value: d.from}, clause.Lt{Column: column, Value: d.to})
+}
+
+// filterKey splits filter[name][op] into its name and op.
+func filterKey(key string) (name string, op Op, ok bool) {
+	if !strings.HasPrefix(key, filterPrefix) || !strings.HasSuffix(key, "]") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(key, filterPrefix), "]"), "][")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		return parts[0], OpEq, true
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return parts[0], Op(strings.ToLower(parts[1])), true
+	}
+	return "", "", false
+}
+
+func supports(kind Kind, op Op) bool {
+	for _, allowed := range ops[kind] {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+func opNames(kind Kind) string {
+	names := make([]string, len(ops[kind]))
+	for i, op := range ops[kind] {
+		names[i] = string(op)
+	}
+	return strings.Join(names, ", ")
+}
+
+// filterValue parses raw as sent, without trimming, since spaces can be part
+// of a value.
+func filterValue(kind Kind, op Op, raw string, loc *time.Location) (interface{}, error) {
+	switch op {
+	case OpNull:
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q must be true or false", raw)
+		}
+		return isNull, nil
+	case OpIn:
+		items := strings.Split(raw, ",")
+		if len(items) > maxInValues {
+			return nil, fmt.Errorf("at most %d values are allowed", maxInValues)
+		}
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			value, err := parseValue(kind, item, loc)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+	return parseValue(kind, raw, loc)
+}
+
+func parseValue(kind Kind, raw string, loc *time.Location) (interface{}, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("value must not be empty")
+	}
+	switch kind {
+	case KindInt:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return n, nil
+	case KindTime:
+		date, err := utils.ParseDateTime(raw, loc)
+		if err != nil {
+			return nil, err
+		}
+		return timeValue(date), nil
+	}
+	return raw, nil
+}
+
+func timeValue(date utils.DateTime) interface{} {
+	if date.Day {
+		return day{from: date.From, to: date.To}
+	}
+	return date.From
+}
+
+// escapeLike makes % and _ in client input match themselves.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func invalidFilter(msg string) error {
+	return exception.NewBadRequestHandler(msg).WithCode(exception.CodeInvalidFilter)
+}
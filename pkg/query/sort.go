@@ -0,0 +1,119 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"scylla/pkg/exception"
+)
+
+// Nulls places NULL values in a sorted column.
+type Nulls string
+
+const (
+	NullsDefault Nulls = ""
+	NullsFirst   Nulls = "NULLS FIRST"
+	NullsLast    Nulls = "NULLS LAST"
+)
+
+// Sort orders a list by one column.
+type Sort struct {
+	// Field is the API name the client sent, Column the column it maps to.
+	Field  string
+	Column string
+	Desc   bool
+	Nulls  Nulls
+}
+
+// Fields maps the API names of a resource to columns. Only the names listed
+// can be sorted on, so client input never reaches SQL as an identifier.
+type Fields map[string]string
+
+// Names returns the API names in alphabetical order.
+func (f Fields) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseSort parses a sort parameter such as
+// "created_at:desc:nulls_last,username" against fields. Direction defaults to
+// ascending and NULL placement to the database default. An empty raw returns
+// fallback. Unknown fields, directions or NULL placements are reported as a
+// bad request listing what is allowed.
+func ParseSort(raw string, fields Fields, fallback ...Sort) ([]Sort, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	var sorts []Sort
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		tokens := strings.Split(strings.TrimSpace(part), ":")
+		if len(tokens) > 3 {
+			return nil, invalidSort(fmt.Sprintf("sort %q must be field[:asc|desc][:nulls_first|nulls_last]", part))
+		}
+
+		name := tokens[0]
+		column, ok := fields[name]
+		if !ok {
+			return nil, invalidSort(fmt.Sprintf("cannot sort by %q, allowed fields: %s", name, strings.Join(fields.Names(), ", ")))
+		}
+		if seen[name] {
+			return nil, invalidSort(fmt.Sprintf("%q is sorted on more than once", name))
+		}
+		seen[name] = true
+
+		s := Sort{Field: name, Column: column}
+		if len(tokens) > 1 {
+			switch strings.ToLower(tokens[1]) {
+			case "asc":
+			case "desc":
+				s.Desc = true
+			default:
+				return nil, invalidSort(fmt.Sprintf("sort direction %q of %q must be asc or desc", tokens[1], name))
+			}
+		}
+		if len(tokens) > 2 {
+			switch strings.ToLower(tokens[2]) {
+			case "nulls_first":
+				s.Nulls = NullsFirst
+			case "nulls_last":
+				s.Nulls = NullsLast
+			default:
+				return nil, invalidSort(fmt.Sprintf("null placement %q of %q must be nulls_first or nulls_last", tokens[2], name))
+			}
+		}
+		sorts = append(sorts, s)
+	}
+	return sorts, nil
+}
+
+// OrderBy renders sorts as an ORDER BY clause with quoted columns, for use
+// with gorm's Clauses.
+func OrderBy(sorts []Sort) clause.Expression {
+	var sql []string
+	var vars []interface{}
+	for _, s := range sorts {
+		term := "? ASC"
+		if s.Desc {
+			term = "? DESC"
+		}
+		if s.Nulls != NullsDefault {
+			term += " " + string(s.Nulls)
+		}
+		sql = append(sql, term)
+		vars = append(vars, clause.Column{Name: s.Column})
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(sql, ", "), Vars: vars, WithoutParentheses: true}}
+}
+
+func invalidSort(msg string) error {
+	return exception.NewBadRequestHandler(msg).WithCode(exception.CodeInvalidSort)
+}
@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fiberCarrier reads the request headers and writes the response headers, so
+// the same carrier extracts the incoming traceparent and returns ours.
+type fiberCarrier struct {
+	ctx *fiber.Ctx
+}
+
+func (c fiberCarrier) Get(key string) string {
+	return c.ctx.Get(key)
+}
+
+func (c fiberCarrier) Set(key, value string) {
+	c.ctx.Set(key, value)
+}
+
+func (c fiberCarrier) Keys() []string {
+	keys := make([]string, 0)
+	for key := range c.ctx.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Middleware starts a server span per request, continuing the trace of an
+// incoming traceparent header and returning traceparent on the response. The
+// trace id replaces the request id local and X-Request-ID header, so trace_id
+// in responses matches the trace. Requests to the skip paths, such as probes,
+// are not traced. Registered outermost, so a panic recovered further in is
+// recorded on the span.
+func Middleware(skip ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if slices.Contains(skip, ctx.Path()) {
+			return ctx.Next()
+		}
+		carrier := fiberCarrier{ctx: ctx}
+		parent := otel.GetTextMapPropagator().Extract(ctx.UserContext(), carrier)
+		spanCtx, span := tracer().Start(parent, ctx.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Method()),
+				semconv.URLPath(ctx.Path()),
+				semconv.ClientAddress(ctx.IP()),
+			),
+		)
+		defer span.End()
+
+		traceId := span.SpanContext().TraceID().String()
+		ctx.SetUserContext(spanCtx)
+		ctx.Locals(spanKey, span)
+		ctx.Locals(utils.RequestIdKey, traceId)
+		ctx.Set(fiber.HeaderXRequestID, traceId)
+		otel.GetTextMapPropagator().Inject(spanCtx, carrier)
+
+		err := ctx.Next()
+
+		route := ctx.Route().Path
+		// the error handler renders err only after the outermost middleware
+		// returns, so the response does not hold its status yet
+		status := ctx.Response().StatusCode()
+		if err != nil {
+			status = exception.Status(err)
+		}
+		span.SetName(ctx.Method() + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if err != nil {
+			span.RecordError(err)
+		}
+		// client errors are not failures of a server span
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		return err
+	}
+}
@@ -3,13 +3,59 @@ package utils
 import (
 	"context"
 	"scylla/dto"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RequestIdKey = "requestid"
+	TenantIdKey  = "tenantid"
+	UserKey      = "user"
+	DBKey        = "db"
+	TimezoneKey  = "timezone"
 )
 
 func ResponseInterceptor(ctx context.Context, resp *dto.Response) {
-	traceIdInf := ctx.Value("requestid")
-	traceId := ""
-	if traceIdInf != nil {
-		traceId = traceIdInf.(string)
+	resp.TraceID = GetTraceId(ctx)
+}
+
+func GetTraceId(ctx context.Context) string {
+	traceId, _ := ctx.Value(RequestIdKey).(string)
+	return traceId
+}
+
+// GetTenantId returns the DMS customer id resolved for the current request by
+// middleware.Tenant, or false when no tenant could be resolved.
+func GetTenantId(ctx context.Context) (string, bool) {
+	tenantId, _ := ctx.Value(TenantIdKey).(string)
+	return tenantId, tenantId != ""
+}
+
+// GetUserId returns the id of the authenticated principal the auth middleware
+// stored under UserKey, when it exposes one.
+func GetUserId(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(UserKey).(interface{ UserID() string })
+	if !ok || principal.UserID() == "" {
+		return "", false
 	}
-	resp.TraceID = traceId
+	return principal.UserID(), true
+}
+
+// WithTenantId returns a copy of ctx carrying tenantId, for work that runs
+// outside an HTTP request such as background sync jobs.
+func WithTenantId(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, TenantIdKey, tenantId)
+}
+
+// GetDB returns the database handle middleware.Database or WithDB stored in
+// ctx, so validators and repositories can join the request transaction.
+func GetDB(ctx context.Context) (*gorm.DB, bool) {
+	db, ok := ctx.Value(DBKey).(*gorm.DB)
+	return db, ok && db != nil
+}
+
+// WithDB returns a copy of ctx carrying db, typically a transaction whose
+// statements database validators should see.
+func WithDB(ctx context.Context, db *gorm.DB) context.Context {
+	return context.WithValue(ctx, DBKey, db)
 }
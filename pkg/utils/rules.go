@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	kilobyte = 1 << 10
+	megabyte = 1 << 20
+	gigabyte = 1 << 30
+)
+
+// dateLayout is the layout of date-only request fields.
+const dateLayout = "2006-01-02"
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// notEmptySlice passes slices of T with at least one element and no zero
+// values.
+func notEmptySlice[T comparable](fl validator.FieldLevel) bool {
+	values, ok := fl.Field().Interface().([]T)
+	if !ok || len(values) == 0 {
+		return false
+	}
+	var zero T
+	for _, value := range values {
+		if value == zero {
+			return false
+		}
+	}
+	return true
+}
+
+func equalField(fl validator.FieldLevel) bool {
+	field := fl.Parent().FieldByName(fl.Param())
+	if !field.IsValid() {
+		return false
+	}
+	return field.Interface() == fl.Field().Interface()
+}
+
+func base64Image(fl validator.FieldLevel) bool {
+	data := fl.Field().String()
+
+	if !strings.HasPrefix(data, "data:image/") {
+		return false
+	}
+
+	base64Data := strings.SplitN(data, ",", 2)
+	if len(base64Data) != 2 {
+		return false
+	}
+
+	_, err := base64.StdEncoding.DecodeString(base64Data[1])
+	return err == nil
+}
+
+// phoneE164 passes numbers such as +6281234567890: a plus sign, a country code
+// not starting with 0 and at most 15 digits in total.
+func phoneE164(fl validator.FieldLevel) bool {
+	return e164Pattern.MatchString(fl.Field().String())
+}
+
+// dateTime passes strings ParseDateTime accepts in the request timezone:
+// dates, RFC 3339 times and times without an offset.
+func dateTime(ctx context.Context, fl validator.FieldLevel) bool {
+	_, err := ParseDateTime(fl.Field().String(), GetLocation(ctx))
+	return err == nil
+}
+
+// dateGteField passes when the field is not before the field named by the
+// param. Both may be time.Time or strings ParseDateTime accepts, read in the
+// request timezone as the filters they bound are; an empty string on either
+// side passes so optional bounds can be combined with omitempty.
+func dateGteField(ctx context.Context, fl validator.FieldLevel) bool {
+	other := fl.Parent().FieldByName(fl.Param())
+	if !other.IsValid() {
+		return false
+	}
+	loc := GetLocation(ctx)
+	date, ok, err := dateValue(fl.Field(), loc)
+	if err != nil {
+		return false
+	}
+	bound, boundOk, err := dateValue(other, loc)
+	if err != nil {
+		return false
+	}
+	if !ok || !boundOk {
+		return true
+	}
+	// a date bound covers its whole day
+	if date.Day {
+		return date.To.After(bound.From)
+	}
+	return !date.From.Before(bound.From)
+}
+
+func dateValue(value reflect.Value, loc *time.Location) (date DateTime, ok bool, err error) {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return date, false, nil
+		}
+		value = value.Elem()
+	}
+	switch v := value.Interface().(type) {
+	case time.Time:
+		return DateTime{From: v, To: v}, !v.IsZero(), nil
+	case string:
+		if v == "" {
+			return date, false, nil
+		}
+		date, err = ParseDateTime(v, loc)
+		return date, err == nil, err
+	}
+	return date, false, nil
+}
+
+// fileKind recognises a file format from its content rather than from the
+// name or the Content-Type the client sent.
+type fileKind struct {
+	name  string
+	sniff func(file multipart.File, size int64, head []byte) bool
+}
+
+var (
+	fileJpeg = fileKind{name: "jpeg", sniff: func(_ multipart.File, _ int64, head []byte) bool {
+		return bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF})
+	}}
+	filePng = fileKind{name: "png", sniff: func(_ multipart.File, _ int64, head []byte) bool {
+		return bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n"))
+	}}
+	// xls workbooks are OLE2 compound documents.
+	fileXls = fileKind{name: "xls", sniff: func(_ multipart.File, _ int64, head []byte) bool {
+		return bytes.HasPrefix(head, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	}}
+	// xlsx workbooks are zip archives holding xl/workbook.xml.
+	fileXlsx = fileKind{name: "xlsx", sniff: func(file multipart.File, size int64, head []byte) bool {
+		if !bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+			return false
+		}
+		archive, err := zip.NewReader(file, size)
+		if err != nil {
+			return false
+		}
+		for _, entry := range archive.File {
+			if entry.Name == "xl/workbook.xml" {
+				return true
+			}
+		}
+		return false
+	}}
+)
+
+var fileKinds = map[string]fileKind{
+	"jpeg": fileJpeg,
+	"jpg":  fileJpeg,
+	"png":  filePng,
+	"xls":  fileXls,
+	"xlsx": fileXlsx,
+}
+
+// file passes uploads of at most maxSize bytes whose content is one of kinds.
+func file(maxSize int64, kinds ...fileKind) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		header := fileHeader(fl)
+		if header == nil || header.Size > maxSize {
+			return false
+		}
+		return sniffFile(header, kinds)
+	}
+}
+
+// fileTypeParam takes the allowed kinds as a space separated param, e.g.
+// fileType=xlsx xls.
+func fileTypeParam(fl validator.FieldLevel) bool {
+	header := fileHeader(fl)
+	if header == nil {
+		return false
+	}
+	var kinds []fileKind
+	for _, name := range strings.Fields(fl.Param()) {
+		kind, ok := fileKinds[strings.ToLower(name)]
+		if !ok {
+			return false
+		}
+		kinds = append(kinds, kind)
+	}
+	return sniffFile(header, kinds)
+}
+
+// maxFileSizeParam takes the limit as param with an optional KB, MB or GB
+// suffix, e.g. maxFileSize=5MB.
+func maxFileSizeParam(fl validator.FieldLevel) bool {
+	header := fileHeader(fl)
+	if header == nil {
+		return false
+	}
+	limit, err := parseSize(fl.Param())
+	if err != nil {
+		return false
+	}
+	return header.Size <= limit
+}
+
+func sniffFile(header *multipart.FileHeader, kinds []fileKind) bool {
+	file, err := header.Open()
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	head = head[:n]
+
+	for _, kind := range kinds {
+		if kind.sniff(file, header.Size, head) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileHeader returns the uploaded file of the field; validator hands pointer
+// fields over dereferenced.
+func fileHeader(fl validator.FieldLevel) *multipart.FileHeader {
+	switch header := fl.Field().Interface().(type) {
+	case *multipart.FileHeader:
+		return header
+	case multipart.FileHeader:
+		return &header
+	}
+	return nil
+}
+
+func parseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	unit := int64(1)
+	for suffix, size := range map[string]int64{"KB": kilobyte, "MB": megabyte, "GB": gigabyte} {
+		if strings.HasSuffix(value, suffix) {
+			value, unit = strings.TrimSuffix(value, suffix), size
+			break
+		}
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return size * unit, err
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestDateGteFieldLocation checks that date bounds are compared in the
+// request timezone: 2024-01-31 in Jakarta ends at 17:00 UTC, before a start
+// of 20:00 UTC, although the UTC day would still include it.
+func TestDateGteFieldLocation(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip(err)
+	}
+	request := struct {
+		StartDate string `validate:"dateTime"`
+		EndDate   string `validate:"dateTime,dateGteField=StartDate"`
+	}{StartDate: "2024-01-31T20:00:00Z", EndDate: "2024-01-31"}
+
+	validate := InitializeValidator()
+	if err := validate.StructCtx(context.Background(), request); err != nil {
+		t.Errorf("UTC: %v", err)
+	}
+	if err := validate.StructCtx(WithLocation(context.Background(), jakarta), request); err == nil {
+		t.Error("Asia/Jakarta: expected EndDate before StartDate to fail")
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// localDateTimeLayout is a time without offset, read in the request timezone.
+const localDateTimeLayout = "2006-01-02T15:04:05"
+
+// DateTime is a point in time or a whole day sent by a client.
+type DateTime struct {
+	// From is the instant, or the first instant of the day.
+	From time.Time
+	// To is the first instant after the day; equal to From for instants.
+	To  time.Time
+	Day bool
+}
+
+// ParseDateTime parses an RFC 3339 time, a time without offset such as
+// 2024-01-31T08:00:00 or 2024-01-31 08:00:00, or a date such as 2024-01-31
+// meaning the whole day. Values without an offset are read in loc.
+func ParseDateTime(raw string, loc *time.Location) (DateTime, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return DateTime{From: t, To: t}, nil
+	}
+	if t, err := time.ParseInLocation(localDateTimeLayout, strings.Replace(raw, " ", "T", 1), loc); err == nil {
+		return DateTime{From: t, To: t}, nil
+	}
+	if t, err := time.ParseInLocation(dateLayout, raw, loc); err == nil {
+		return DateTime{From: t, To: t.AddDate(0, 0, 1), Day: true}, nil
+	}
+	return DateTime{}, fmt.Errorf("%q is not a date (2006-01-02) or an RFC 3339 time", raw)
+}
+
+// FormatTime renders t as RFC 3339 in loc, the form of every timestamp in
+// responses.
+func FormatTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// GetLocation returns the timezone middleware.Timezone resolved for the
+// request, or UTC outside a request.
+func GetLocation(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(TimezoneKey).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// WithLocation returns a copy of ctx rendering and reading times in loc.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, TimezoneKey, loc)
+}
+
+// LoadLocation resolves an IANA name such as Asia/Jakarta or a fixed offset
+// such as +07:00.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if t, err := time.Parse("-07:00", name); err == nil {
+		_, offset := t.Zone()
+		return time.FixedZone(name, offset), nil
+	}
+	if name == "" || strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("timezone %q is not an IANA name or an offset", name)
+	}
+	return time.LoadLocation(name)
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// messages holds the template of a validation tag per locale. {0} is replaced
+// by the field name and {1} by the tag parameter.
+type messages map[string]string
+
+const fallbackKey = "fallback"
+
+// fallbackMessages are used for tags without a template in the locale.
+var fallbackMessages = messages{
+	"en": "{0} failed the {1} rule",
+	"id": "{0} tidak memenuhi aturan {1}",
+}
+
+// builtinMessages fill tags the bundled Indonesian translations lack.
+var builtinMessages = map[string]messages{
+	"datetime":         {"id": "{0} harus sesuai format {1}"},
+	"required_if":      {"id": "{0} wajib diisi jika {1}"},
+	"required_unless":  {"id": "{0} wajib diisi kecuali {1}"},
+	"required_with":    {"id": "{0} wajib diisi jika {1} diisi"},
+	"required_without": {"id": "{0} wajib diisi jika {1} kosong"},
+	"boolean":          {"id": "{0} harus berupa nilai boolean"},
+	"e164":             {"id": "{0} harus berupa nomor telepon format E.164"},
+}
+
+// newTranslator registers the default English and Indonesian messages of the
+// validator built-in tags. English is the fallback locale.
+func newTranslator(validate *validator.Validate) *ut.UniversalTranslator {
+	english := en.New()
+	uni := ut.New(english, english, id.New())
+
+	enTrans, _ := uni.GetTranslator("en")
+	idTrans, _ := uni.GetTranslator("id")
+	_ = en_translations.RegisterDefaultTranslations(validate, enTrans)
+	_ = id_translations.RegisterDefaultTranslations(validate, idTrans)
+
+	for locale, text := range fallbackMessages {
+		trans, _ := uni.GetTranslator(locale)
+		_ = trans.Add(fallbackKey, text, false)
+	}
+
+	for tag, msgs := range builtinMessages {
+		registerMessages(validate, uni, tag, msgs)
+	}
+	return uni
+}
+
+// registerMessages sets the templates of tag, replacing the default ones.
+func registerMessages(validate *validator.Validate, translator *ut.UniversalTranslator, tag string, msgs messages) {
+	for locale, text := range msgs {
+		trans, found := translator.GetTranslator(locale)
+		if !found {
+			continue
+		}
+		text := text
+		_ = validate.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+			return trans.Add(tag, text, true)
+		}, func(trans ut.Translator, fe validator.FieldError) string {
+			msg, err := trans.T(fe.Tag(), fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return msg
+		})
+	}
+}
+
+// TranslateError renders fe in the language acceptLanguage prefers, falling
+// back to English and, for tags without a template, to a generic message.
+func (v *Validator) TranslateError(fe validator.FieldError, acceptLanguage string) string {
+	trans := v.Translator(acceptLanguage)
+	if msg := fe.Translate(trans); msg != fe.Error() {
+		return msg
+	}
+	msg, err := trans.T(fallbackKey, fe.Field(), fe.Tag())
+	if err != nil {
+		return fe.Error()
+	}
+	return msg
+}
+
+// Translator picks the translator of the first supported language of an
+// Accept-Language header, matching "id-ID" to "id".
+func (v *Validator) Translator(acceptLanguage string) ut.Translator {
+	for _, lang := range preferredLanguages(acceptLanguage) {
+		lang = strings.ToLower(strings.ReplaceAll(lang, "-", "_"))
+		if trans, found := v.translator.GetTranslator(lang); found {
+			return trans
+		}
+		if base, _, ok := strings.Cut(lang, "_"); ok {
+			if trans, found := v.translator.GetTranslator(base); found {
+				return trans
+			}
+		}
+	}
+	return v.translator.GetFallback()
+}
+
+// preferredLanguages lists the language ranges of an Accept-Language header by
+// descending quality, dropping those with q=0.
+func preferredLanguages(header string) []string {
+	type weighted struct {
+		lang    string
+		quality float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if lang == "" || lang == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			langs = append(langs, weighted{lang: lang, quality: quality})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].quality > langs[j].quality })
+
+	result := make([]string, len(langs))
+	for i, l := range langs {
+		result[i] = l.lang
+	}
+	return result
+}
@@ -1,41 +1,174 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"scylla/pkg/engine"
-	"strings"
 )
 
-func IsUnique(fl validator.FieldLevel) bool {
-	return unique(fl)
-}
-
-func unique(fl validator.FieldLevel) bool {
-	var count int64
-	param := fl.Param()
-	params := strings.Split(param, ";")
-	switch len(params) {
-	case 1:
-		return true
-	case 2:
-		dField := fl.Field().String()
-		engine.Instance.Table(params[0]).Where(fmt.Sprintf("%s = ?", params[1]), dField).Debug().Count(&count)
-		if count > 0 {
+// lookupTable describes a table the unique and exists validators may query.
+type lookupTable struct {
+	// columns lists the columns tags may name.
+	columns []string
+	// tenant is the column holding the DMS customer id; rows of other tenants
+	// are ignored and lookups without a tenant fail.
+	tenant string
+	// softDelete is the column set when a row is deleted; such rows are
+	// ignored.
+	softDelete string
+}
+
+// lookupTables whitelists the identifiers tags may reference, since they end
+// up in SQL. Identifiers are quoted as well.
+var lookupTables = map[string]lookupTable{
+	"customers": {
+		columns: []string{"id", "username", "email", "phone"},
+	},
+	"vehicles": {
+		columns: []string{"id", "vehicle_id", "vehicle_no"},
+		tenant:  "cust_id",
+	},
+	"customer_vehicles": {
+		columns: []string{"id", "customer_id", "vehicle_id"},
+		tenant:  "cust_id",
+	},
+}
+
+// unique passes when no other row has the field value:
+// unique=table;column, or unique=table;column;key to ignore the row whose key
+// column equals the struct field of the same name, as on updates.
+func unique(ctx context.Context, fl validator.FieldLevel) bool {
+	params := strings.Split(fl.Param(), ";")
+	if len(params) < 2 || len(params) > 3 {
+		return false
+	}
+	query, ok := lookup(ctx, params[0], params[1], fl.Field().Interface())
+	if !ok {
+		return false
+	}
+	if len(params) == 3 {
+		if !allowedColumn(params[0], params[2]) {
 			return false
-		} else {
-			return true
 		}
-	case 3:
-		ignore := fl.Parent().FieldByName(params[2]).String()
-		dField := fl.Field().String()
-		engine.Instance.Table(params[0]).Where(fmt.Sprintf("%s = ?", params[1]), dField).Not(map[string]any{fmt.Sprintf("%s", strings.ToLower(params[2])): []string{ignore}}).Count(&count)
-		if count > 0 {
+		ignore, ok := fieldByName(fl.Parent(), params[2])
+		if !ok {
 			return false
-		} else {
+		}
+		query = query.Where(clause.Neq{Column: clause.Column{Name: params[2]}, Value: ignore.Interface()})
+	}
+	found, err := rowExists(query)
+	if err != nil {
+		lookupFailed(ctx, fmt.Errorf("unique validation on %s: %w", params[0], err))
+		return false
+	}
+	return !found
+}
+
+// exists passes when a row has the field value: exists=table;column.
+func exists(ctx context.Context, fl validator.FieldLevel) bool {
+	params := strings.Split(fl.Param(), ";")
+	if len(params) != 2 {
+		return false
+	}
+	query, ok := lookup(ctx, params[0], params[1], fl.Field().Interface())
+	if !ok {
+		return false
+	}
+	found, err := rowExists(query)
+	if err != nil {
+		lookupFailed(ctx, fmt.Errorf("exists validation on %s: %w", params[0], err))
+		return false
+	}
+	return found
+}
+
+// lookup builds the query for rows of table whose column equals value, scoped
+// to the request tenant and to rows not soft-deleted. It uses the database
+// handle of ctx, falling back to engine.Instance outside requests.
+func lookup(ctx context.Context, table, column string, value interface{}) (*gorm.DB, bool) {
+	config, ok := lookupTables[table]
+	if !ok || !allowedColumn(table, column) {
+		slog.WarnContext(ctx, "validation references a table or column not whitelisted", slog.String("table", table), slog.String("column", column))
+		return nil, false
+	}
+
+	db, ok := GetDB(ctx)
+	if !ok {
+		db = engine.Instance
+	}
+	if db == nil {
+		lookupFailed(ctx, errors.New("validation lookup without a database handle"))
+		return nil, false
+	}
+
+	query := db.WithContext(ctx).Table(table).Where(clause.Eq{Column: clause.Column{Name: column}, Value: value})
+	if config.tenant != "" {
+		tenantId, ok := GetTenantId(ctx)
+		if !ok {
+			return nil, false
+		}
+		query = query.Where(clause.Eq{Column: clause.Column{Name: config.tenant}, Value: tenantId})
+	}
+	if config.softDelete != "" {
+		query = query.Where(clause.Eq{Column: clause.Column{Name: config.softDelete}, Value: nil})
+	}
+	return query, true
+}
+
+// lookupErrorKey holds the *lookupError of a Validator.StructCtx call.
+type lookupErrorKey struct{}
+
+type lookupError struct {
+	err error
+}
+
+// lookupFailed records err for Validator.StructCtx to return instead of the
+// validation result, so an unreachable database is a 500 rather than a value
+// reported as taken or missing. Outside StructCtx it is only logged.
+func lookupFailed(ctx context.Context, err error) {
+	if holder, ok := ctx.Value(lookupErrorKey{}).(*lookupError); ok {
+		if holder.err == nil {
+			holder.err = err
+		}
+		return
+	}
+	slog.ErrorContext(ctx, "validation lookup failed", slog.String("error", err.Error()))
+}
+
+func rowExists(query *gorm.DB) (bool, error) {
+	var one int
+	result := query.Select("1").Limit(1).Scan(&one)
+	return result.RowsAffected > 0, result.Error
+}
+
+func allowedColumn(table, column string) bool {
+	for _, allowed := range lookupTables[table].columns {
+		if allowed == column {
 			return true
 		}
-	default:
-		return true
 	}
+	return false
+}
+
+// fieldByName finds a struct field by Go name ignoring case, so the column
+// name "id" matches the field ID.
+func fieldByName(parent reflect.Value, name string) (reflect.Value, bool) {
+	for parent.Kind() == reflect.Ptr {
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	field := parent.FieldByNameFunc(func(field string) bool {
+		return strings.EqualFold(field, name)
+	})
+	return field, field.IsValid()
 }
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestUniqueLookupError checks that a database the unique rule cannot reach
+// fails StructCtx with that error rather than a validation error, which
+// would tell the client the value is already taken.
+func TestUniqueLookupError(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 connect_timeout=1 sslmode=disable"}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := struct {
+		Email string `validate:"unique=customers;email"`
+	}{Email: "someone@example.com"}
+	err = InitializeValidator().StructCtx(WithDB(context.Background(), db), request)
+	if err == nil {
+		t.Fatal("expected the lookup error")
+	}
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		t.Fatalf("lookup error reported as validation failure: %v", err)
+	}
+}
@@ -1,89 +1,191 @@
 package utils
 
 import (
-	"encoding/base64"
-	"github.com/go-playground/validator/v10"
-	"mime/multipart"
+	"context"
 	"reflect"
 	"strings"
-)
-
-func InitializeValidator() *validator.Validate {
-	validate := validator.New()
-
-	_ = validate.RegisterValidation("sliceString", func(fl validator.FieldLevel) bool {
-		slices := fl.Field().Interface().([]string)
-		if len(slices) == 0 {
-			return false
-		}
-		for _, s := range slices {
-			if s == "" {
-				return false
-			}
-		}
-		return true
-	})
 
-	_ = validate.RegisterValidation("sliceInt", func(fl validator.FieldLevel) bool {
-		slices := fl.Field().Interface().([]int)
-		if len(slices) == 0 {
-			return false
-		}
-		for _, val := range slices {
-			if val == 0 {
-				return false
-			}
-		}
-		return true
-	})
-
-	_ = validate.RegisterValidation("equal", func(fl validator.FieldLevel) bool {
-		fieldName := fl.Param()
-		field := fl.Parent().FieldByName(fieldName)
-		if !field.IsValid() {
-			return false
-		}
-		return field.Interface() == fl.Field().Interface()
-	})
-
-	_ = validate.RegisterValidation("image", func(fl validator.FieldLevel) bool {
-		file, ok := fl.Field().Interface().(*multipart.FileHeader)
-		if !ok || file == nil {
-			return false
-		}
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
 
-		allowedTypes := []string{"image/jpeg", "image/png", "image/jpg"}
-		if !contains(allowedTypes, file.Header.Get("Content-Type")) {
-			return false
-		}
+// Validator is a validator.Validate together with the translator holding the
+// messages of its tags, so validators built separately do not share them.
+type Validator struct {
+	*validator.Validate
+	translator *ut.UniversalTranslator
+}
 
-		const maxSize = 5 * 1024 * 1024
-		if file.Size > maxSize {
-			return false
-		}
+// StructCtx validates s like validator.Validate.StructCtx, except that a
+// database lookup failing in a unique or exists rule is returned as that
+// error instead of a validation failure.
+func (v *Validator) StructCtx(ctx context.Context, s interface{}) error {
+	holder := &lookupError{}
+	err := v.Validate.StructCtx(context.WithValue(ctx, lookupErrorKey{}, holder), s)
+	if holder.err != nil {
+		return holder.err
+	}
+	return err
+}
 
-		return true
-	})
+// rule is a custom validation tag, registered by InitializeValidator together
+// with its messages.
+type rule struct {
+	tag string
+	fn  validator.Func
+	// fnCtx is set instead of fn by rules that need the request context
+	// passed to validate.StructCtx.
+	fnCtx    validator.FuncCtx
+	messages messages
+}
 
-	_ = validate.RegisterValidation("base64Image", func(fl validator.FieldLevel) bool {
-		data := fl.Field().String()
+// rules is the registry of the custom tags DTOs may use. TestDtoValidateTags
+// fails when a DTO references a tag that is neither here nor built in.
+var rules = []rule{
+	{
+		tag: "notEmptyIntSlice",
+		fn:  notEmptySlice[int],
+		messages: messages{
+			"en": "{0} must contain at least one value and no zeros",
+			"id": "{0} harus berisi minimal satu nilai tanpa angka nol",
+		},
+	},
+	{
+		tag: "notEmptyInt64Slice",
+		fn:  notEmptySlice[int64],
+		messages: messages{
+			"en": "{0} must contain at least one value and no zeros",
+			"id": "{0} harus berisi minimal satu nilai tanpa angka nol",
+		},
+	},
+	{
+		tag: "notEmptyStringSlice",
+		fn:  notEmptySlice[string],
+		messages: messages{
+			"en": "{0} must contain at least one value and no empty text",
+			"id": "{0} harus berisi minimal satu nilai tanpa teks kosong",
+		},
+	},
+	{
+		tag: "sliceString",
+		fn:  notEmptySlice[string],
+		messages: messages{
+			"en": "{0} must contain at least one value and no empty text",
+			"id": "{0} harus berisi minimal satu nilai tanpa teks kosong",
+		},
+	},
+	{
+		tag: "sliceInt",
+		fn:  notEmptySlice[int],
+		messages: messages{
+			"en": "{0} must contain at least one value and no zeros",
+			"id": "{0} harus berisi minimal satu nilai tanpa angka nol",
+		},
+	},
+	{
+		tag: "equal",
+		fn:  equalField,
+		messages: messages{
+			"en": "{0} must match {1}",
+			"id": "{0} harus sama dengan {1}",
+		},
+	},
+	{
+		tag: "fileType",
+		fn:  fileTypeParam,
+		messages: messages{
+			"en": "{0} must be a file of type {1}",
+			"id": "{0} harus berupa file bertipe {1}",
+		},
+	},
+	{
+		tag: "maxFileSize",
+		fn:  maxFileSizeParam,
+		messages: messages{
+			"en": "{0} must be at most {1}",
+			"id": "ukuran {0} maksimal {1}",
+		},
+	},
+	{
+		tag: "image",
+		fn:  file(5*megabyte, fileJpeg, filePng),
+		messages: messages{
+			"en": "{0} must be a jpg, jpeg or png image of at most 5 MB",
+			"id": "{0} harus berupa gambar jpg, jpeg atau png maksimal 5 MB",
+		},
+	},
+	{
+		tag: "allowedMimeTypeExcel",
+		fn:  file(10*megabyte, fileXlsx, fileXls),
+		messages: messages{
+			"en": "{0} must be an xlsx or xls file of at most 10 MB",
+			"id": "{0} harus berupa file xlsx atau xls maksimal 10 MB",
+		},
+	},
+	{
+		tag: "base64Image",
+		fn:  base64Image,
+		messages: messages{
+			"en": "{0} must be a base64 encoded image",
+			"id": "{0} harus berupa gambar yang di-encode base64",
+		},
+	},
+	{
+		tag: "phone",
+		fn:  phoneE164,
+		messages: messages{
+			"en": "{0} must be a phone number in E.164 format, e.g. +6281234567890",
+			"id": "{0} harus berupa nomor telepon format E.164, contoh +6281234567890",
+		},
+	},
+	{
+		tag:   "dateTime",
+		fnCtx: dateTime,
+		messages: messages{
+			"en": "{0} must be a date such as 2024-01-31 or an RFC 3339 time such as 2024-01-31T08:00:00+07:00",
+			"id": "{0} harus berupa tanggal seperti 2024-01-31 atau waktu RFC 3339 seperti 2024-01-31T08:00:00+07:00",
+		},
+	},
+	{
+		tag:   "dateGteField",
+		fnCtx: dateGteField,
+		messages: messages{
+			"en": "{0} must not be before {1}",
+			"id": "{0} tidak boleh sebelum {1}",
+		},
+	},
+	{
+		tag:   "unique",
+		fnCtx: unique,
+		messages: messages{
+			"en": "{0} has already been taken",
+			"id": "{0} sudah digunakan",
+		},
+	},
+	{
+		tag:   "exists",
+		fnCtx: exists,
+		messages: messages{
+			"en": "{0} does not exist",
+			"id": "{0} tidak ditemukan",
+		},
+	},
+}
 
-		if !strings.HasPrefix(data, "data:image/") {
-			return false
-		}
+// InitializeValidator registers the custom validation tags together with
+// their English and Indonesian messages.
+func InitializeValidator() *Validator {
+	validate := validator.New()
+	translator := newTranslator(validate)
 
-		base64Data := strings.SplitN(data, ",", 2)
-		if len(base64Data) != 2 {
-			return false
+	for _, r := range rules {
+		if r.fnCtx != nil {
+			_ = validate.RegisterValidationCtx(r.tag, r.fnCtx)
+		} else {
+			_ = validate.RegisterValidation(r.tag, r.fn)
 		}
-
-		_, err := base64.StdEncoding.DecodeString(base64Data[1])
-		return err == nil
-	})
-
-	_ = validate.RegisterValidation("unique", func(fl validator.FieldLevel) bool {
-		return IsUnique(fl)
-	})
+		registerMessages(validate, translator, r.tag, r.messages)
+	}
 
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
@@ -93,14 +195,5 @@ func InitializeValidator() *validator.Validate {
 		return name
 	})
 
-	return validate
-}
-
-func contains(slice []string, item string) bool {
-	for _, v := range slice {
-		if v == item {
-			return true
-		}
-	}
-	return false
+	return &Validator{Validate: validate, translator: translator}
 }
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// TestDtoValidateTags checks that every validate tag in dto only references
+// rules registered by InitializeValidator or built into the validator, so a
+// typo fails the tests instead of panicking at request time with "Undefined
+// validation function".
+func TestDtoValidateTags(t *testing.T) {
+	validate := InitializeValidator()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "../../dto", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checked := 0
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				field, ok := node.(*ast.Field)
+				if !ok || field.Tag == nil {
+					return true
+				}
+				raw, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					return true
+				}
+				tag, ok := reflect.StructTag(raw).Lookup("validate")
+				if !ok || tag == "" || tag == "-" {
+					return true
+				}
+				checked++
+				if err := checkTag(validate.Validate, tag); err != nil {
+					t.Errorf("%s: %s: %v", fset.Position(field.Pos()), fieldName(field), err)
+				}
+				return true
+			})
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no validate tags found in dto")
+	}
+}
+
+// checkTag parses tag the way validate.Struct does; the validator panics on
+// unknown rules while parsing, before any value is looked at.
+func checkTag(validate *validator.Validate, tag string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s", strings.TrimSpace(fmt.Sprint(r)))
+		}
+	}()
+	_ = validate.Var(nil, tag)
+	return nil
+}
+
+func fieldName(field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return "embedded field"
+	}
+	return field.Names[0].Name
+}
@@ -2,13 +2,18 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"html"
+	"log/slog"
 	"scylla/dto"
 	"scylla/entity"
-	"scylla/pkg/helper"
+	"scylla/pkg/exception"
+	"scylla/pkg/query"
+	"scylla/pkg/utils"
 	"strings"
+	"time"
 )
 
 type CustomerRepo interface {
@@ -17,24 +22,23 @@ type CustomerRepo interface {
 	Update(ctx context.Context, data entity.Customer) error
 	DeleteBatch(ctx context.Context, Id []int) error
 	FindById(ctx context.Context, Id int) (data entity.Customer, err error)
-	FindAll(ctx context.Context, dataFilter dto.CustomerQueryFilter) (domain []dto.CustomerResponse, count int64)
-	CheckColumnExists(ctx context.Context, column string, value interface{}) bool
+	FindAll(ctx context.Context, dataFilter dto.CustomerQueryFilter, created *CreatedRange, fields query.Fieldset) (domain []entity.Customer, page query.Page, err error)
+	Search(ctx context.Context, q string, limit int) ([]dto.CustomerSearchResponse, error)
+	CheckColumnExists(ctx context.Context, column string, value interface{}) (bool, error)
 }
 
 type CustomerRepoImpl struct {
-	db *gorm.DB
+	db      *gorm.DB
+	cursors *query.Cursors
+	log     *slog.Logger
 }
 
-func NewCustomerRepoImpl(db *gorm.DB) CustomerRepo {
-	return &CustomerRepoImpl{db: db}
+func NewCustomerRepoImpl(db *gorm.DB, cursors *query.Cursors, log *slog.Logger) CustomerRepo {
+	return &CustomerRepoImpl{db: db, cursors: cursors, log: log}
 }
 
 func (repo *CustomerRepoImpl) Insert(ctx context.Context, data entity.Customer) error {
-	result := repo.db.WithContext(ctx).Create(&data)
-	if result.Error != nil {
-		helper.ErrorPanic(result.Error)
-	}
-	return nil
+	return exception.FromDatabase(repo.db.WithContext(ctx).Create(&data).Error, "customer")
 }
 
 func (repo *CustomerRepoImpl) InsertBatch(ctx context.Context, data []entity.Customer, batchSize int) error {
@@ -44,24 +48,22 @@ func (repo *CustomerRepoImpl) InsertBatch(ctx context.Context, data []entity.Cus
 	}
 
 	if err := tx.CreateInBatches(&data, batchSize).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		return err
+		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
+			repo.log.WarnContext(ctx, "customer batch rollback failed", slog.String("error", rollbackErr.Error()))
+		}
+		return exception.FromDatabase(err, "customer")
 	}
 
-	return nil
+	return exception.FromDatabase(tx.Commit().Error, "customer")
 }
 
 func (repo *CustomerRepoImpl) Update(ctx context.Context, data entity.Customer) error {
 	result := repo.db.WithContext(ctx).Updates(&data)
-	if result.RowsAffected == 0 {
-		return errors.New("record not found")
-	}
 	if result.Error != nil {
-		helper.ErrorPanic(result.Error)
+		return exception.FromDatabase(result.Error, "customer")
+	}
+	if result.RowsAffected == 0 {
+		return exception.NewNotFoundHandler("customer not found").WithCode(exception.CodeCustomerNotFound)
 	}
 
 	return nil
@@ -70,102 +72,295 @@ func (repo *CustomerRepoImpl) Update(ctx context.Context, data entity.Customer)
 func (repo *CustomerRepoImpl) DeleteBatch(ctx context.Context, Id []int) error {
 	var data entity.Customer
 	result := repo.db.WithContext(ctx).Where("id IN (?)", Id).Delete(&data)
-	if result.RowsAffected == 0 {
-		return errors.New("record not found")
-	}
 	if result.Error != nil {
-		helper.ErrorPanic(result.Error)
+		return exception.FromDatabase(result.Error, "customer")
+	}
+	if result.RowsAffected == 0 {
+		return exception.NewNotFoundHandler("customer not found").WithCode(exception.CodeCustomerNotFound)
 	}
 
 	return nil
 }
 
 func (repo *CustomerRepoImpl) FindById(ctx context.Context, Id int) (data entity.Customer, err error) {
-	result := repo.db.WithContext(ctx).First(&data, Id)
-	if result.RowsAffected == 0 {
-		return data, errors.New("record not found")
-	}
-	if result.Error != nil {
-		return data, result.Error
-	}
+	err = repo.db.WithContext(ctx).First(&data, Id).Error
+	return data, exception.FromDatabase(err, "customer")
+}
 
-	return data, nil
+// customerColumns are the columns behind the fields of dto.CustomerResponse.
+// Lists can be sorted on and select any of them.
+var customerColumns = query.Fields{
+	"id":         "id",
+	"username":   "username",
+	"email":      "email",
+	"phone":      "phone",
+	"address":    "address",
+	"created_at": "created_at",
 }
 
-func (repo *CustomerRepoImpl) FindAll(ctx context.Context, dataFilter dto.CustomerQueryFilter) (domain []dto.CustomerResponse, count int64) {
-	rawQuery := `
-        SELECT 
-            id, username, email, phone, address, created_at
-        FROM 
-            customers
-    `
+var customerFilterFields = query.FilterFields{
+	"id":         {Column: "id", Kind: query.KindInt},
+	"username":   {Column: "username"},
+	"email":      {Column: "email"},
+	"phone":      {Column: "phone"},
+	"address":    {Column: "address"},
+	"created_at": {Column: "created_at", Kind: query.KindTime},
+}
 
-	var filters []string
-	var args []interface{}
+// customerKeysetFields are the sort fields cursors support. Keyset conditions
+// need columns that are never NULL.
+var customerKeysetFields = query.Fields{
+	"id":         "id",
+	"created_at": "created_at",
+}
 
-	if dataFilter.Username != "" {
-		filters = append(filters, "username LIKE ?")
-		args = append(args, "%"+dataFilter.Username+"%")
+// FindAll lists customers by page, or after dataFilter.Cursor when set. Pages
+// sorted only by keyset fields carry cursors to their neighbours. Only the
+// columns of fields are selected, besides the id and sort columns.
+func (repo *CustomerRepoImpl) FindAll(ctx context.Context, dataFilter dto.CustomerQueryFilter, created *CreatedRange, fields query.Fieldset) (domain []entity.Customer, page query.Page, err error) {
+	sortFields := customerColumns
+	if dataFilter.Cursor != "" {
+		sortFields = customerKeysetFields
 	}
-	if dataFilter.Email != "" {
-		filters = append(filters, "email LIKE ?")
-		args = append(args, "%"+dataFilter.Email+"%")
+	sorts, err := query.ParseSort(dataFilter.Sort, sortFields, query.Sort{Field: "id", Column: "id", Desc: true})
+	if err != nil {
+		return nil, page, err
 	}
-	if dataFilter.StartDate != "" && dataFilter.EndDate != "" {
-		filters = append(filters, "created_at BETWEEN ? AND ?")
-		args = append(args, dataFilter.StartDate, dataFilter.EndDate)
+	sorts = query.WithTiebreaker(sorts, "id", "id")
+
+	var cursor query.Cursor
+	if dataFilter.Cursor != "" {
+		if cursor, err = repo.cursors.Decode(dataFilter.Cursor, sorts); err != nil {
+			return nil, page, err
+		}
 	}
 
-	if len(filters) > 0 {
-		rawQuery += " WHERE " + strings.Join(filters, " AND ")
+	filters, err := query.ParseFilter(dataFilter.Filter, customerFilterFields, utils.GetLocation(ctx))
+	if err != nil {
+		return nil, page, err
 	}
+	filters = append(filters, created.filters(filters)...)
 
-	countQuery := "SELECT COUNT(*) FROM (" + rawQuery + ") AS subquery"
-	resultCount := repo.db.Raw(countQuery, args...).WithContext(ctx).Scan(&count)
-	helper.ErrorPanic(resultCount.Error)
+	db := repo.db.WithContext(ctx).Table("customers")
+	for _, filter := range filters {
+		db = db.Where(filter.Expression())
+	}
+	// the legacy username and email parameters keep their case-sensitive match
+	if dataFilter.Username != "" {
+		db = db.Where("username LIKE ?", "%"+dataFilter.Username+"%")
+	}
+	if dataFilter.Email != "" {
+		db = db.Where("email LIKE ?", "%"+dataFilter.Email+"%")
+	}
+	db = db.Session(&gorm.Session{})
 
-	sortBy := "id DESC"
-	if dataFilter.Sort != "" {
-		var sortClauses []string
-		for _, row := range strings.Split(dataFilter.Sort, ",") {
-			colSort := strings.Split(row, ":")
-			if len(colSort) > 1 {
-				sortClauses = append(sortClauses, fmt.Sprintf("%s %s", colSort[0], colSort[1]))
-			}
-		}
-		if len(sortClauses) > 0 {
-			sortBy = strings.Join(sortClauses, ", ")
+	if dataFilter.IncludeTotal == nil || *dataFilter.IncludeTotal {
+		var count int64
+		if err := db.Count(&count).Error; err != nil {
+			return nil, page, err
 		}
+		page.Total = &count
+	}
+
+	required := []string{"id"}
+	for _, s := range sorts {
+		required = append(required, s.Column)
+	}
+	db = db.Select(fields.Columns(customerColumns, required...))
+	if dataFilter.All {
+		err = db.Clauses(query.OrderBy(sorts)).Scan(&domain).Error
+		return domain, page, err
 	}
-	rawQuery += " ORDER BY " + sortBy
 
-	if dataFilter.All == true {
-		result := repo.db.Raw(rawQuery, args...).WithContext(ctx).Scan(&domain)
-		helper.ErrorPanic(result.Error)
+	if dataFilter.Limit == 0 {
+		dataFilter.Limit = 10
+	}
+	order := sorts
+	if dataFilter.Cursor != "" {
+		if cursor.Backward {
+			order = query.Reverse(sorts)
+		}
+		db = db.Where(query.Keyset(order, cursor.Values))
 	} else {
 		if dataFilter.Page == 0 {
 			dataFilter.Page = 1
 		}
-		if dataFilter.Limit == 0 {
-			dataFilter.Limit = 10
+		db = db.Offset((dataFilter.Page - 1) * dataFilter.Limit)
+	}
+
+	// one extra row tells whether another page follows
+	if err = db.Clauses(query.OrderBy(order)).Limit(dataFilter.Limit + 1).Scan(&domain).Error; err != nil {
+		return nil, page, err
+	}
+	more := len(domain) > dataFilter.Limit
+	if more {
+		domain = domain[:dataFilter.Limit]
+	}
+	if cursor.Backward {
+		for i, j := 0, len(domain)-1; i < j; i, j = i+1, j-1 {
+			domain[i], domain[j] = domain[j], domain[i]
+		}
+	}
+
+	if len(domain) > 0 && keysetOnly(sorts, customerKeysetFields) {
+		hasNext, hasPrev := more, dataFilter.Page > 1
+		if dataFilter.Cursor != "" {
+			hasNext, hasPrev = more || cursor.Backward, !cursor.Backward || more
 		}
+		if hasNext {
+			page.NextCursor = repo.cursors.Encode(sorts, customerKey(domain[len(domain)-1], sorts), false)
+		}
+		if hasPrev {
+			page.PrevCursor = repo.cursors.Encode(sorts, customerKey(domain[0], sorts), true)
+		}
+	}
+	return domain, page, nil
+}
 
-		offset := (dataFilter.Page - 1) * dataFilter.Limit
-		rawQuery += fmt.Sprintf(" LIMIT %d OFFSET %d", dataFilter.Limit, offset)
+// CreatedRange bounds created_at by the start_date and end_date parameters,
+// which predate the filter ones and, as before, only apply together.
+type CreatedRange struct {
+	From utils.DateTime
+	To   utils.DateTime
+}
 
-		result := repo.db.Raw(rawQuery, args...).WithContext(ctx).Scan(&domain)
-		helper.ErrorPanic(result.Error)
+// filters returns the created_at filters of r not already among filters; a nil
+// range has none.
+func (r *CreatedRange) filters(filters []query.Filter) []query.Filter {
+	if r == nil {
+		return nil
 	}
+	var bounds []query.Filter
+	if !query.Has(filters, "created_at", query.OpGte) {
+		bounds = append(bounds, query.TimeFilter("created_at", "created_at", query.OpGte, r.From))
+	}
+	if !query.Has(filters, "created_at", query.OpLte) {
+		bounds = append(bounds, query.TimeFilter("created_at", "created_at", query.OpLte, r.To))
+	}
+	return bounds
+}
+
+func customerKey(customer entity.Customer, sorts []query.Sort) []interface{} {
+	values := make([]interface{}, len(sorts))
+	for i, s := range sorts {
+		switch s.Column {
+		case "id":
+			values[i] = customer.ID
+		case "created_at":
+			values[i] = customer.CreatedAt.Format(time.RFC3339Nano)
+		}
+	}
+	return values
+}
 
-	return domain, count
+func keysetOnly(sorts []query.Sort, fields query.Fields) bool {
+	for _, s := range sorts {
+		if _, ok := fields[s.Field]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
-func (repo *CustomerRepoImpl) CheckColumnExists(ctx context.Context, column string, value interface{}) bool {
-	var exists bool
-	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM customers WHERE %s = ?)", column)
-	err := repo.db.WithContext(ctx).Raw(query, value).Scan(&exists).Error
+// customerSearchSQL ranks full-text matches on the search_vector column,
+// trigram matches on each column and names that sound like the query. See
+// the add_customer_search migration for the indexes behind each condition.
+const customerSearchSQL = `
+WITH search AS (SELECT to_tsquery('simple', @tsquery) AS query)
+SELECT c.id, c.username, c.email, c.phone, c.address, c.created_at,
+	ts_rank(c.search_vector, s.query)
+		+ coalesce(greatest(similarity(c.username, @q), similarity(c.email, @q), similarity(c.phone, @q), similarity(c.address, @q)), 0)
+		+ CASE WHEN dmetaphone(c.username) = nullif(dmetaphone(@q), '') THEN 0.3 ELSE 0 END AS rank,
+	CASE WHEN to_tsvector('simple', coalesce(c.username, '')) @@ s.query THEN ts_headline('simple', c.username, s.query, @headline) END AS username_highlight,
+	CASE WHEN to_tsvector('simple', coalesce(c.email, '')) @@ s.query THEN ts_headline('simple', c.email, s.query, @headline) END AS email_highlight,
+	CASE WHEN to_tsvector('simple', coalesce(c.phone, '')) @@ s.query THEN ts_headline('simple', c.phone, s.query, @headline) END AS phone_highlight,
+	CASE WHEN to_tsvector('simple', coalesce(c.address, '')) @@ s.query THEN ts_headline('simple', c.address, s.query, @headline) END AS address_highlight
+FROM customers c, search s
+WHERE c.search_vector @@ s.query
+	OR c.username % @q OR c.email % @q OR c.phone % @q OR c.address % @q
+	OR dmetaphone(c.username) = nullif(dmetaphone(@q), '')
+ORDER BY rank DESC, c.id DESC
+LIMIT @limit`
+
+// ts_headline marks matches with control characters so the text can be HTML
+// escaped before they become <mark> tags.
+const (
+	markStart = "\x02"
+	markStop  = "\x03"
+)
+
+var searchHeadline = fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`, markStart, markStop)
+
+type customerSearchRow struct {
+	entity.Customer
+	Rank              float64
+	UsernameHighlight *string
+	EmailHighlight    *string
+	PhoneHighlight    *string
+	AddressHighlight  *string
+}
+
+func (repo *CustomerRepoImpl) Search(ctx context.Context, q string, limit int) ([]dto.CustomerSearchResponse, error) {
+	var rows []customerSearchRow
+	err := repo.db.WithContext(ctx).Raw(customerSearchSQL, map[string]interface{}{
+		"q":        q,
+		"tsquery":  prefixQuery(q),
+		"headline": searchHeadline,
+		"limit":    limit,
+	}).Scan(&rows).Error
 	if err != nil {
-		return false
+		return nil, err
+	}
+
+	loc := utils.GetLocation(ctx)
+	result := make([]dto.CustomerSearchResponse, len(rows))
+	for i, row := range rows {
+		result[i] = dto.CustomerSearchResponse{CustomerResponse: dto.CustomerResponseFromCustomer(row.Customer, loc), Rank: row.Rank}
+		for field, text := range map[string]*string{
+			"username": row.UsernameHighlight,
+			"email":    row.EmailHighlight,
+			"phone":    row.PhoneHighlight,
+			"address":  row.AddressHighlight,
+		} {
+			if text == nil {
+				continue
+			}
+			if result[i].Highlights == nil {
+				result[i].Highlights = map[string]string{}
+			}
+			result[i].Highlights[field] = highlight(*text)
+		}
+	}
+	return result, nil
+}
+
+// prefixQuery turns the words of q into a tsquery matching documents that
+// contain every word, each also as a prefix: "jo ban" finds "John Bandung".
+// Words are quoted so tsquery operators in q are plain text.
+func prefixQuery(q string) string {
+	words := strings.Fields(q)
+	for i, word := range words {
+		word = strings.NewReplacer(`\`, `\\`, "'", "''").Replace(word)
+		words[i] = "'" + word + "':*"
+	}
+	return strings.Join(words, " & ")
+}
+
+func highlight(text string) string {
+	text = html.EscapeString(text)
+	return strings.NewReplacer(markStart, "<mark>", markStop, "</mark>").Replace(text)
+}
+
+// CheckColumnExists reports whether a customer has value in column, which
+// must be one of customerColumns; it is quoted rather than interpolated.
+func (repo *CustomerRepoImpl) CheckColumnExists(ctx context.Context, column string, value interface{}) (bool, error) {
+	name, ok := customerColumns[column]
+	if !ok {
+		return false, fmt.Errorf("customers cannot be looked up by %q", column)
 	}
-	return exists
+	var exists bool
+	err := repo.db.WithContext(ctx).
+		Raw("SELECT EXISTS(SELECT 1 FROM customers WHERE ? = ?)", clause.Column{Name: name}, value).
+		Scan(&exists).Error
+	return exists, err
 }
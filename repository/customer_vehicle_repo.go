@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"log/slog"
+	"scylla/entity"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"time"
+)
+
+type CustomerVehicleRepo interface {
+	Insert(ctx context.Context, data *entity.CustomerVehicle) error
+	Update(ctx context.Context, data entity.CustomerVehicle) error
+	Delete(ctx context.Context, Id int) error
+	FindByCustomerId(ctx context.Context, customerId int, activeOn *time.Time) ([]entity.CustomerVehicle, error)
+	FindByCustomerIds(ctx context.Context, customerIds []int) ([]entity.CustomerVehicle, error)
+	FindOverlapping(ctx context.Context, custId string, vehicleId int64, from time.Time, to *time.Time) ([]entity.CustomerVehicle, error)
+	FindOpen(ctx context.Context, customerId int, vehicleId int64, on time.Time) ([]entity.CustomerVehicle, error)
+}
+
+type CustomerVehicleRepoImpl struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewCustomerVehicleRepoImpl(db *gorm.DB, log *slog.Logger) CustomerVehicleRepo {
+	return &CustomerVehicleRepoImpl{db: db, log: log}
+}
+
+func (repo *CustomerVehicleRepoImpl) Insert(ctx context.Context, data *entity.CustomerVehicle) error {
+	return exception.FromDatabase(repo.db.WithContext(ctx).Omit("updated_at").Create(data).Error, "vehicle assignment")
+}
+
+func (repo *CustomerVehicleRepoImpl) Update(ctx context.Context, data entity.CustomerVehicle) error {
+	return exception.FromDatabase(repo.db.WithContext(ctx).Save(&data).Error, "vehicle assignment")
+}
+
+func (repo *CustomerVehicleRepoImpl) Delete(ctx context.Context, Id int) error {
+	result := repo.db.WithContext(ctx).Delete(&entity.CustomerVehicle{}, Id)
+	if result.Error == nil {
+		repo.log.DebugContext(ctx, "customer vehicle assignment deleted", slog.Int("id", Id))
+	}
+	return result.Error
+}
+
+// FindByCustomerId lists the assignments of a customer within the request
+// tenant, restricted to the ones effective on activeOn when it is set.
+func (repo *CustomerVehicleRepoImpl) FindByCustomerId(ctx context.Context, customerId int, activeOn *time.Time) (data []entity.CustomerVehicle, err error) {
+	custId, err := tenantId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := repo.db.WithContext(ctx).Where("cust_id = ? AND customer_id = ?", custId, customerId)
+	if activeOn != nil {
+		query = query.Where("effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", *activeOn, *activeOn)
+	}
+	err = query.Order("effective_from DESC, id DESC").Find(&data).Error
+	return data, err
+}
+
+// FindByCustomerIds lists the assignments of several customers within the
+// request tenant in one query, for embedding them in a customer list.
+func (repo *CustomerVehicleRepoImpl) FindByCustomerIds(ctx context.Context, customerIds []int) (data []entity.CustomerVehicle, err error) {
+	if len(customerIds) == 0 {
+		return nil, nil
+	}
+	custId, err := tenantId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = repo.db.WithContext(ctx).
+		Where("cust_id = ? AND customer_id IN ?", custId, customerIds).
+		Order("customer_id, effective_from DESC, id DESC").
+		Find(&data).Error
+	return data, err
+}
+
+// FindOverlapping returns the assignments of a vehicle whose inclusive date
+// range intersects [from, to]. A nil to means open-ended.
+func (repo *CustomerVehicleRepoImpl) FindOverlapping(ctx context.Context, custId string, vehicleId int64, from time.Time, to *time.Time) (data []entity.CustomerVehicle, err error) {
+	query := repo.db.WithContext(ctx).
+		Where("cust_id = ? AND vehicle_id = ?", custId, vehicleId).
+		Where("effective_to IS NULL OR effective_to >= ?", from)
+	if to != nil {
+		query = query.Where("effective_from <= ?", *to)
+	}
+	err = query.Find(&data).Error
+	return data, err
+}
+
+// FindOpen returns the assignments of a vehicle to a customer within the
+// request tenant that have not ended before on.
+func (repo *CustomerVehicleRepoImpl) FindOpen(ctx context.Context, customerId int, vehicleId int64, on time.Time) (data []entity.CustomerVehicle, err error) {
+	custId, err := tenantId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = repo.db.WithContext(ctx).
+		Where("cust_id = ? AND customer_id = ? AND vehicle_id = ?", custId, customerId, vehicleId).
+		Where("effective_to IS NULL OR effective_to >= ?", on).
+		Find(&data).Error
+	return data, err
+}
+
+// tenantId is the DMS customer id assignments are scoped to, so a lookup by
+// customer never reaches another tenant's fleet.
+func tenantId(ctx context.Context) (string, error) {
+	custId, ok := utils.GetTenantId(ctx)
+	if !ok {
+		return "", exception.NewUnauthorizedHandler("tenant could not be resolved").WithCode(exception.CodeTenantRequired)
+	}
+	return custId, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+	"scylla/entity"
+	"time"
+)
+
+type VehicleRepo interface {
+	Upsert(ctx context.Context, data []entity.Vehicle) (int64, error)
+	DeactivateMissing(ctx context.Context, custId string, syncedBefore time.Time) (int64, error)
+	InsertSyncRun(ctx context.Context, data *entity.VehicleSyncRun) error
+	UpdateSyncRun(ctx context.Context, data entity.VehicleSyncRun) error
+}
+
+type VehicleRepoImpl struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+func NewVehicleRepoImpl(db *gorm.DB, log *slog.Logger) VehicleRepo {
+	return &VehicleRepoImpl{db: db, log: log}
+}
+
+func (repo *VehicleRepoImpl) Upsert(ctx context.Context, data []entity.Vehicle) (int64, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	result := repo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "cust_id"}, {Name: "vehicle_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"vehicle_no", "vehicle_desc", "vehicle_type_name", "length", "width", "height", "volume",
+			"driver_id", "helper_id", "driver_name", "helper_name", "is_active", "synced_at", "updated_at",
+		}),
+	}).Omit("id", "created_at").Create(&data)
+
+	return result.RowsAffected, result.Error
+}
+
+// DeactivateMissing marks every active vehicle of the tenant that was not seen
+// by the sync run started at syncedBefore as inactive.
+func (repo *VehicleRepoImpl) DeactivateMissing(ctx context.Context, custId string, syncedBefore time.Time) (int64, error) {
+	result := repo.db.WithContext(ctx).Model(&entity.Vehicle{}).
+		Where("cust_id = ? AND is_active = ? AND synced_at < ?", custId, true, syncedBefore).
+		Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()})
+	if result.Error == nil && result.RowsAffected > 0 {
+		repo.log.DebugContext(ctx, "deactivated vehicles missing upstream", slog.Int64("rows", result.RowsAffected))
+	}
+
+	return result.RowsAffected, result.Error
+}
+
+func (repo *VehicleRepoImpl) InsertSyncRun(ctx context.Context, data *entity.VehicleSyncRun) error {
+	return repo.db.WithContext(ctx).Create(data).Error
+}
+
+func (repo *VehicleRepoImpl) UpdateSyncRun(ctx context.Context, data entity.VehicleSyncRun) error {
+	return repo.db.WithContext(ctx).Save(&data).Error
+}
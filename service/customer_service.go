@@ -2,90 +2,87 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/go-playground/validator/v10"
 	"github.com/xuri/excelize/v2"
-	"golang.org/x/sync/errgroup"
+	"log/slog"
 	"math"
 	"scylla/dto"
 	"scylla/entity"
 	"scylla/pkg/exception"
 	"scylla/pkg/helper"
+	"scylla/pkg/metrics"
+	"scylla/pkg/query"
+	"scylla/pkg/utils"
 	"scylla/repository"
+	"slices"
 	"strings"
 	"time"
 )
 
 type CustomerService interface {
-	Create(ctx context.Context, request dto.CreateCustomerRequest)
-	CreateBatch(ctx context.Context, request dto.CreateCustomerBatchRequest)
-	Update(ctx context.Context, request dto.UpdateCustomerRequest)
-	DeleteBatch(ctx context.Context, request dto.DeleteBatchCustomerRequest)
-	FindById(ctx context.Context, request dto.CustomerParams) (response dto.CustomerResponse)
-	FindAll(ctx context.Context, dataFilter dto.CustomerQueryFilter) (response []dto.CustomerResponse, paging dto.Meta)
+	Create(ctx context.Context, request dto.CreateCustomerRequest) error
+	CreateBatch(ctx context.Context, request dto.CreateCustomerBatchRequest) error
+	Update(ctx context.Context, request dto.UpdateCustomerRequest) error
+	DeleteBatch(ctx context.Context, request dto.DeleteBatchCustomerRequest) error
+	FindById(ctx context.Context, request dto.CustomerParams) (response dto.CustomerResponse, err error)
+	// FindAll returns []dto.CustomerResponse, or maps holding only the asked
+	// fields when dataFilter.Fields is set.
+	FindAll(ctx context.Context, dataFilter dto.CustomerQueryFilter) (response interface{}, paging dto.Meta, err error)
+	Search(ctx context.Context, request dto.CustomerSearchQuery) (response []dto.CustomerSearchResponse, err error)
 	Export(ctx context.Context, dataFilter dto.CustomerQueryFilter) (string, error)
 	Import(ctx context.Context, request dto.UploadCustomerRequest) error
+	AssignVehicle(ctx context.Context, request dto.AssignVehicleRequest) (response dto.CustomerVehicleResponse, err error)
+	UnassignVehicle(ctx context.Context, request dto.UnassignVehicleRequest) error
+	FindVehicles(ctx context.Context, request dto.CustomerParams, dataFilter dto.CustomerVehicleQueryFilter) (response []dto.CustomerVehicleResponse, err error)
 }
 
 type CustomerServiceImpl struct {
-	customerRepo repository.CustomerRepo
-	validate     *validator.Validate
+	customerRepo        repository.CustomerRepo
+	customerVehicleRepo repository.CustomerVehicleRepo
+	dmsService          DmsService
+	validate            *utils.Validator
+	log                 *slog.Logger
 }
 
-func NewCustomerServiceImpl(customerRepo repository.CustomerRepo, validate *validator.Validate) CustomerService {
+func NewCustomerServiceImpl(customerRepo repository.CustomerRepo, customerVehicleRepo repository.CustomerVehicleRepo, dmsService DmsService, validate *utils.Validator, log *slog.Logger) CustomerService {
 	return &CustomerServiceImpl{
-		customerRepo: customerRepo,
-		validate:     validate,
+		customerRepo:        customerRepo,
+		customerVehicleRepo: customerVehicleRepo,
+		dmsService:          dmsService,
+		validate:            validate,
+		log:                 log,
 	}
 }
 
-func (service *CustomerServiceImpl) Create(ctx context.Context, request dto.CreateCustomerRequest) {
-	err := service.validate.Struct(request)
-	helper.ErrorPanic(err)
-
-	dataset := entity.Customer{
-		Username: request.Username,
-		Email:    request.Email,
-		Phone:    request.Phone,
-		Address:  request.Address,
+func (service *CustomerServiceImpl) Create(ctx context.Context, request dto.CreateCustomerRequest) error {
+	if err := service.validate.StructCtx(ctx, request); err != nil {
+		return err
 	}
 
-	err = service.customerRepo.Insert(ctx, dataset)
-	if err != nil {
-		panic(exception.NewInternalServerErrorHandler(err.Error()))
-	}
+	return service.customerRepo.Insert(ctx, dto.CustomerFromCreateCustomerRequest(request))
 }
 
-func (service *CustomerServiceImpl) CreateBatch(ctx context.Context, request dto.CreateCustomerBatchRequest) {
-	err := service.validate.Struct(request)
-	helper.ErrorPanic(err)
-
-	var customers []entity.Customer
-	for _, req := range request.Customers {
-		customer := entity.Customer{
-			Username: req.Username,
-			Email:    req.Email,
-			Phone:    req.Phone,
-			Address:  req.Address,
-		}
-		customers = append(customers, customer)
+func (service *CustomerServiceImpl) CreateBatch(ctx context.Context, request dto.CreateCustomerBatchRequest) error {
+	if err := service.validate.StructCtx(ctx, request); err != nil {
+		return err
 	}
 
+	customers := dto.CustomersFromCreateCustomerRequests(request.Customers)
+
 	batchSize := len(request.Customers)
 
-	err = service.customerRepo.InsertBatch(ctx, customers, batchSize)
-	if err != nil {
-		panic(exception.NewInternalServerErrorHandler(err.Error()))
-	}
+	return service.customerRepo.InsertBatch(ctx, customers, batchSize)
 }
 
-func (service *CustomerServiceImpl) Update(ctx context.Context, request dto.UpdateCustomerRequest) {
-	err := service.validate.Struct(request)
-	helper.ErrorPanic(err)
+func (service *CustomerServiceImpl) Update(ctx context.Context, request dto.UpdateCustomerRequest) error {
+	if err := service.validate.StructCtx(ctx, request); err != nil {
+		return err
+	}
 
 	dataset, err := service.customerRepo.FindById(ctx, request.ID)
 	if err != nil {
-		panic(exception.NewNotFoundHandler(err.Error()))
+		return err
 	}
 
 	dataset.Username = request.Username
@@ -93,42 +90,72 @@ func (service *CustomerServiceImpl) Update(ctx context.Context, request dto.Upda
 	dataset.Phone = request.Phone
 	dataset.Address = request.Address
 
-	err = service.customerRepo.Update(ctx, dataset)
-	if err != nil {
-		panic(exception.NewNotFoundHandler(err.Error()))
-	}
+	return service.customerRepo.Update(ctx, dataset)
 }
 
-func (service *CustomerServiceImpl) DeleteBatch(ctx context.Context, request dto.DeleteBatchCustomerRequest) {
-	err := service.validate.Struct(request)
-	helper.ErrorPanic(err)
-
-	err = service.customerRepo.DeleteBatch(ctx, request.ID)
-	if err != nil {
-		panic(exception.NewNotFoundHandler(err.Error()))
+func (service *CustomerServiceImpl) DeleteBatch(ctx context.Context, request dto.DeleteBatchCustomerRequest) error {
+	if err := service.validate.StructCtx(ctx, request); err != nil {
+		return err
 	}
+
+	return service.customerRepo.DeleteBatch(ctx, request.ID)
 }
 
-func (service *CustomerServiceImpl) FindById(ctx context.Context, request dto.CustomerParams) (response dto.CustomerResponse) {
+// FindById returns the customer with its vehicle assignments. Assignments
+// belong to a tenant, so without one the customer comes without them.
+func (service *CustomerServiceImpl) FindById(ctx context.Context, request dto.CustomerParams) (response dto.CustomerResponse, err error) {
 	result, err := service.customerRepo.FindById(ctx, request.CustomerId)
+	if err != nil {
+		return response, err
+	}
+
+	response = dto.CustomerResponseFromCustomer(result, utils.GetLocation(ctx))
+	if _, ok := utils.GetTenantId(ctx); !ok {
+		return response, nil
+	}
 
+	assignments, err := service.customerVehicleRepo.FindByCustomerId(ctx, result.ID, nil)
 	if err != nil {
-		panic(exception.NewNotFoundHandler(err.Error()))
+		return response, err
 	}
+	response.Vehicles = dto.CustomerVehicleResponsesFromCustomerVehicles(assignments)
 
-	helper.Automapper(result, &response)
-	return response
+	return response, nil
 }
 
-func (service *CustomerServiceImpl) FindAll(ctx context.Context, dataFilter dto.CustomerQueryFilter) (response []dto.CustomerResponse, paging dto.Meta) {
+// customerIncludes are the relations FindAll can embed.
+var customerIncludes = []string{"vehicles"}
+
+func (service *CustomerServiceImpl) FindAll(ctx context.Context, dataFilter dto.CustomerQueryFilter) (response interface{}, paging dto.Meta, err error) {
+	if err := service.validate.StructCtx(ctx, dataFilter); err != nil {
+		return nil, paging, err
+	}
+
+	fields, err := query.ParseFieldset(dataFilter.Fields, dto.CustomerResponse{})
+	if err != nil {
+		return nil, paging, err
+	}
+	includes, err := query.ParseInclude(dataFilter.Include, customerIncludes...)
+	if err != nil {
+		return nil, paging, err
+	}
+
+	created, err := createdRange(ctx, dataFilter)
+	if err != nil {
+		return nil, paging, err
+	}
 
-	result, total := service.customerRepo.FindAll(ctx, dataFilter)
+	result, page, err := service.customerRepo.FindAll(ctx, dataFilter, created, fields)
+	if err != nil {
+		return nil, paging, err
+	}
 
-	for _, value := range result {
-		var res dto.CustomerResponse
-		helper.Automapper(value, &res)
+	customers := dto.CustomerResponsesFromCustomers(result, utils.GetLocation(ctx))
 
-		response = append(response, res)
+	if slices.Contains(includes, "vehicles") {
+		if err := service.includeVehicles(ctx, customers); err != nil {
+			return nil, paging, err
+		}
 	}
 
 	if dataFilter.Limit == 0 {
@@ -141,17 +168,90 @@ func (service *CustomerServiceImpl) FindAll(ctx context.Context, dataFilter dto.
 
 	paging.Page = dataFilter.Page
 	paging.Limit = dataFilter.Limit
-	paging.TotalData = int(total)
-	paging.TotalPage = int(math.Ceil(float64(total) / float64(dataFilter.Limit)))
+	if page.Total != nil {
+		totalData := int(*page.Total)
+		totalPage := int(math.Ceil(float64(totalData) / float64(dataFilter.Limit)))
+		paging.TotalData = &totalData
+		paging.TotalPage = &totalPage
+	}
+	paging.NextCursor = page.NextCursor
+	paging.PrevCursor = page.PrevCursor
+
+	if fields == nil {
+		return customers, paging, nil
+	}
+	projected := make([]map[string]interface{}, len(customers))
+	for i, customer := range customers {
+		projected[i] = fields.Project(customer, includes...)
+	}
+	return projected, paging, nil
+}
 
-	return response, paging
+// createdRange parses the start_date and end_date parameters in the request
+// timezone, nil unless both are set.
+func createdRange(ctx context.Context, dataFilter dto.CustomerQueryFilter) (*repository.CreatedRange, error) {
+	if dataFilter.StartDate == "" || dataFilter.EndDate == "" {
+		return nil, nil
+	}
+	loc := utils.GetLocation(ctx)
+	from, err := utils.ParseDateTime(dataFilter.StartDate, loc)
+	if err != nil {
+		return nil, exception.NewBadRequestHandler(fmt.Sprintf("start_date: %v", err))
+	}
+	to, err := utils.ParseDateTime(dataFilter.EndDate, loc)
+	if err != nil {
+		return nil, exception.NewBadRequestHandler(fmt.Sprintf("end_date: %v", err))
+	}
+	return &repository.CreatedRange{From: from, To: to}, nil
+}
+
+// includeVehicles loads the vehicle assignments of every customer in one
+// query. Without a tenant there are no assignments to embed.
+func (service *CustomerServiceImpl) includeVehicles(ctx context.Context, customers []dto.CustomerResponse) error {
+	if _, ok := utils.GetTenantId(ctx); !ok {
+		return nil
+	}
+	ids := make([]int, len(customers))
+	for i, customer := range customers {
+		ids[i] = customer.ID
+	}
+
+	assignments, err := service.customerVehicleRepo.FindByCustomerIds(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	byCustomer := make(map[int][]entity.CustomerVehicle, len(customers))
+	for _, assignment := range assignments {
+		byCustomer[assignment.CustomerID] = append(byCustomer[assignment.CustomerID], assignment)
+	}
+	for i := range customers {
+		customers[i].Vehicles = dto.CustomerVehicleResponsesFromCustomerVehicles(byCustomer[customers[i].ID])
+	}
+	return nil
+}
+
+func (service *CustomerServiceImpl) Search(ctx context.Context, request dto.CustomerSearchQuery) (response []dto.CustomerSearchResponse, err error) {
+	if err := service.validate.StructCtx(ctx, request); err != nil {
+		return nil, err
+	}
+
+	if request.Limit == 0 {
+		request.Limit = 20
+	}
+
+	return service.customerRepo.Search(ctx, strings.TrimSpace(request.Q), request.Limit)
 }
 
 func (service *CustomerServiceImpl) Export(ctx context.Context, dataFilter dto.CustomerQueryFilter) (string, error) {
+	if err := service.validate.StructCtx(ctx, dataFilter); err != nil {
+		return "", err
+	}
+
 	excel := excelize.NewFile()
 	defer func() {
 		if err := excel.Close(); err != nil {
-			panic(exception.NewInternalServerErrorHandler(err.Error()))
+			service.log.WarnContext(ctx, "close export workbook failed", slog.Any("error", err))
 		}
 	}()
 
@@ -167,9 +267,20 @@ func (service *CustomerServiceImpl) Export(ctx context.Context, dataFilter dto.C
 		return "", exception.NewInternalServerErrorHandler(err.Error())
 	}
 
-	result, _ := service.customerRepo.FindAll(ctx, dataFilter)
+	// the export never shows the total, so skip counting
+	includeTotal := false
+	dataFilter.IncludeTotal = &includeTotal
+	created, err := createdRange(ctx, dataFilter)
+	if err != nil {
+		return "", err
+	}
 
-	fmt.Println("data", result)
+	result, _, err := service.customerRepo.FindAll(ctx, dataFilter, created, nil)
+	if err != nil {
+		return "", err
+	}
+
+	service.log.DebugContext(ctx, "exporting customers", slog.Int("rows", len(result)))
 
 	// Define headers
 	headers := []string{"ID", "Name", "Email", "Phone", "Address"}
@@ -215,7 +326,23 @@ func (service *CustomerServiceImpl) Export(ctx context.Context, dataFilter dto.C
 	return filePath, nil
 }
 
-func (service *CustomerServiceImpl) Import(ctx context.Context, request dto.UploadCustomerRequest) error {
+// customerImportColumns is the width of the MST_CUSTOMER sheet: username,
+// email, phone and address.
+const customerImportColumns = 4
+
+func (service *CustomerServiceImpl) Import(ctx context.Context, request dto.UploadCustomerRequest) (err error) {
+	defer func() {
+		result := "success"
+		if err != nil {
+			result = "failed"
+		}
+		metrics.ImportJobs.WithLabelValues("customer", result).Inc()
+	}()
+
+	if err := service.validate.StructCtx(ctx, request); err != nil {
+		return err
+	}
+
 	// Open the Excel file from the request
 	src, err := request.File.Open()
 	if err != nil {
@@ -238,12 +365,10 @@ func (service *CustomerServiceImpl) Import(ctx context.Context, request dto.Uplo
 		return exception.NewInternalServerErrorHandler(err.Error())
 	}
 
-	// Use error group to manage concurrent operations
-	g, _ := errgroup.WithContext(ctx)
-	chanUser := make(chan entity.Customer)
+	var customers []entity.Customer
 	excelValidation := exception.ExcelValidation{}
 	uniqueTracker := make(map[string]map[string]bool)
-	rowErrors := map[string][]string{}
+	failedRows := map[int]bool{}
 
 	// Initialize uniqueTracker for each field based on validation rules
 	for _, rule := range helper.RulesExcelCustomer {
@@ -257,6 +382,13 @@ func (service *CustomerServiceImpl) Import(ctx context.Context, request dto.Uplo
 			continue // Skip header row
 		}
 
+		// GetRows drops trailing empty cells; pad so they fail "required"
+		// instead of indexing out of range
+		for len(row) < customerImportColumns {
+			row = append(row, "")
+		}
+		var rowErrors []dto.FieldError
+
 		// Validate each cell in the row based on rules
 		for colIndex, rule := range helper.RulesExcelCustomer {
 			fields := strings.Split(rule, ",")
@@ -268,11 +400,11 @@ func (service *CustomerServiceImpl) Import(ctx context.Context, request dto.Uplo
 				switch r {
 				case "required":
 					if cell == "" {
-						rowErrors[fieldName] = append(rowErrors[fieldName], fmt.Sprintf("%s row %d is required", fieldName, rowIndex+1))
+						rowErrors = append(rowErrors, dto.FieldError{Field: fieldName, Rule: r, Message: fmt.Sprintf("%s is required", fieldName)})
 					}
 				case "unique":
 					if uniqueTracker[fieldName][cell] {
-						rowErrors[fieldName] = append(rowErrors[fieldName], fmt.Sprintf("%s '%s' is not unique row %d", fieldName, cell, rowIndex+1))
+						rowErrors = append(rowErrors, dto.FieldError{Field: fieldName, Rule: r, Message: fmt.Sprintf("%s '%s' is not unique", fieldName, cell)})
 					}
 					uniqueTracker[fieldName][cell] = true
 				}
@@ -288,9 +420,13 @@ func (service *CustomerServiceImpl) Import(ctx context.Context, request dto.Uplo
 			for _, r := range rules {
 				if r == "unique" {
 					cell := row[colIndex]
-					exists := service.customerRepo.CheckColumnExists(ctx, fieldName, cell)
-					if exists != false {
-						excelValidation.AddHandler(fieldName, rowIndex+1, fmt.Sprintf("%s '%s' already taken", fieldName, cell))
+					exists, err := service.customerRepo.CheckColumnExists(ctx, fieldName, cell)
+					if err != nil {
+						return err
+					}
+					if exists {
+						excelValidation.AddHandler(fieldName, r, rowIndex+1, fmt.Sprintf("%s '%s' already taken", fieldName, cell))
+						failedRows[rowIndex+1] = true
 					}
 				}
 			}
@@ -298,59 +434,154 @@ func (service *CustomerServiceImpl) Import(ctx context.Context, request dto.Uplo
 
 		// If there are validation errors for this row, skip further processing
 		if len(rowErrors) > 0 {
-			for field, errs := range rowErrors {
-				for _, err := range errs {
-					excelValidation.AddHandler(field, rowIndex+1, err)
-				}
+			failedRows[rowIndex+1] = true
+			for _, fieldError := range rowErrors {
+				excelValidation.AddHandler(fieldError.Field, fieldError.Rule, rowIndex+1, fieldError.Message)
 			}
 			continue
 		}
 
-		// Process valid rows concurrently
-		g.Go(func() error {
-			if len(row) < 4 {
-				return fmt.Errorf("invalid row length: %v", row)
-			}
-			customer := entity.Customer{
-				Username: row[0],
-				Email:    row[1],
-				Phone:    row[2],
-				Address:  row[3],
-			}
-			chanUser <- customer
-			return nil
+		customers = append(customers, entity.Customer{
+			Username: row[0],
+			Email:    row[1],
+			Phone:    row[2],
+			Address:  row[3],
 		})
 	}
 
-	// Close the channel once all goroutines are done
-	go func() {
-		err := g.Wait()
-		if err != nil {
-			return
+	metrics.ImportRows.WithLabelValues("customer", "processed").Add(float64(max(len(rows)-1, 0)))
+	metrics.ImportRows.WithLabelValues("customer", "failed").Add(float64(len(failedRows)))
+
+	// If there are any validation errors, return them
+	if len(excelValidation.Errors) > 0 {
+		service.log.WarnContext(ctx, "customer import rejected", slog.Int("failed_rows", len(failedRows)))
+		return &excelValidation
+	}
+
+	// Insert batch of customers into the database
+	if err := service.customerRepo.InsertBatch(ctx, customers, len(customers)); err != nil {
+		return err
+	}
+	service.log.InfoContext(ctx, "customers imported", slog.Int("rows", len(customers)))
+
+	return nil
+}
+
+func (service *CustomerServiceImpl) AssignVehicle(ctx context.Context, request dto.AssignVehicleRequest) (response dto.CustomerVehicleResponse, err error) {
+	if err := service.validate.StructCtx(ctx, request); err != nil {
+		return response, err
+	}
+
+	custId, ok := utils.GetTenantId(ctx)
+	if !ok {
+		return response, exception.NewUnauthorizedHandler("tenant could not be resolved").WithCode(exception.CodeTenantRequired)
+	}
+
+	if _, err := service.customerRepo.FindById(ctx, request.CustomerID); err != nil {
+		return response, err
+	}
+
+	effectiveFrom, _ := time.Parse(dateLayout, request.EffectiveFrom)
+	var effectiveTo *time.Time
+	if request.EffectiveTo != "" {
+		date, _ := time.Parse(dateLayout, request.EffectiveTo)
+		if date.Before(effectiveFrom) {
+			return response, exception.NewBadRequestHandler("effective_to must not be before effective_from").WithCode(exception.CodeInvalidDateRange)
 		}
-		close(chanUser)
-	}()
+		effectiveTo = &date
+	}
 
-	// Collect customers from the channel
-	var customers []entity.Customer
-	for customer := range chanUser {
-		customers = append(customers, customer)
+	_, err = service.dmsService.GetVehicleById(ctx, dto.VehicleParams{VehicleId: request.VehicleID})
+	if err != nil {
+		if errors.Is(err, exception.ErrNotFound) {
+			return response, exception.NewBadRequestHandler(fmt.Sprintf("vehicle %d does not exist", request.VehicleID)).WithCode(exception.CodeVehicleNotFound)
+		}
+		return response, err
+	}
+
+	overlapping, err := service.customerVehicleRepo.FindOverlapping(ctx, custId, request.VehicleID, effectiveFrom, effectiveTo)
+	if err != nil {
+		return response, err
+	}
+	if len(overlapping) > 0 {
+		return response, exception.NewConflictHandler(fmt.Sprintf("vehicle %d is already assigned to customer %d from %s",
+			request.VehicleID, overlapping[0].CustomerID, overlapping[0].EffectiveFrom.Format(dateLayout))).WithCode(exception.CodeVehicleAlreadyAssigned)
+	}
+
+	dataset := entity.CustomerVehicle{
+		CustomerID:    request.CustomerID,
+		CustId:        custId,
+		VehicleID:     request.VehicleID,
+		EffectiveFrom: effectiveFrom,
+		EffectiveTo:   effectiveTo,
 	}
 
-	// Wait for all goroutines to finish
-	if err := g.Wait(); err != nil {
+	if err := service.customerVehicleRepo.Insert(ctx, &dataset); err != nil {
+		return response, err
+	}
+
+	return dto.CustomerVehicleResponseFromCustomerVehicle(dataset), nil
+}
+
+// UnassignVehicle ends the open assignments of a vehicle to a customer on
+// effective_to (today by default). Assignments that would only start after
+// that day are removed.
+func (service *CustomerServiceImpl) UnassignVehicle(ctx context.Context, request dto.UnassignVehicleRequest) error {
+	if err := service.validate.StructCtx(ctx, request); err != nil {
 		return err
 	}
 
-	// If there are any validation errors, return them
-	if len(excelValidation.Errors) > 0 {
-		return &excelValidation
+	effectiveTo := today(ctx)
+	if request.EffectiveTo != "" {
+		effectiveTo, _ = time.Parse(dateLayout, request.EffectiveTo)
 	}
 
-	// Insert batch of customers into the database
-	if err := service.customerRepo.InsertBatch(ctx, customers, len(customers)); err != nil {
+	assignments, err := service.customerVehicleRepo.FindOpen(ctx, request.CustomerID, request.VehicleID, effectiveTo)
+	if err != nil {
 		return err
 	}
+	if len(assignments) == 0 {
+		return exception.NewNotFoundHandler("vehicle assignment not found").WithCode(exception.CodeVehicleAssignmentNotFound)
+	}
 
+	for _, assignment := range assignments {
+		if assignment.EffectiveFrom.After(effectiveTo) {
+			err = service.customerVehicleRepo.Delete(ctx, assignment.ID)
+		} else {
+			assignment.EffectiveTo = &effectiveTo
+			err = service.customerVehicleRepo.Update(ctx, assignment)
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+func (service *CustomerServiceImpl) FindVehicles(ctx context.Context, request dto.CustomerParams, dataFilter dto.CustomerVehicleQueryFilter) (response []dto.CustomerVehicleResponse, err error) {
+	if _, err := service.customerRepo.FindById(ctx, request.CustomerId); err != nil {
+		return nil, err
+	}
+
+	var activeOn *time.Time
+	if dataFilter.Active {
+		date := today(ctx)
+		activeOn = &date
+	}
+
+	assignments, err := service.customerVehicleRepo.FindByCustomerId(ctx, request.CustomerId, activeOn)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.CustomerVehicleResponsesFromCustomerVehicles(assignments), nil
+}
+
+const dateLayout = "2006-01-02"
+
+// today is the current date in the request timezone, as the midnight UTC
+// that DATE columns are read as.
+func today(ctx context.Context) time.Time {
+	date, _ := time.Parse(dateLayout, time.Now().In(utils.GetLocation(ctx)).Format(dateLayout))
+	return date
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"scylla/dto"
+	"scylla/entity"
+	"scylla/pkg/query"
+	"scylla/pkg/utils"
+	"scylla/repository"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// listedCustomers stands in for the customers table; only FindAll is used.
+type listedCustomers struct {
+	repository.CustomerRepo
+	customers []entity.Customer
+}
+
+func (repo listedCustomers) FindAll(context.Context, dto.CustomerQueryFilter, *repository.CreatedRange, query.Fieldset) ([]entity.Customer, query.Page, error) {
+	return repo.customers, query.Page{}, nil
+}
+
+type recordedQuery struct {
+	sql  string
+	vars []interface{}
+}
+
+// TestFindAllIncludeVehicles checks that include=vehicles loads the
+// assignments of the whole page with one query scoped to the request tenant,
+// rather than one per customer, and none without a tenant.
+func TestFindAllIncludeVehicles(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 sslmode=disable"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries []recordedQuery
+	err = db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		queries = append(queries, recordedQuery{sql: tx.Statement.SQL.String(), vars: tx.Statement.Vars})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	customers := listedCustomers{customers: []entity.Customer{{ID: 1}, {ID: 2}, {ID: 3}}}
+	service := NewCustomerServiceImpl(customers, repository.NewCustomerVehicleRepoImpl(db, log), nil, utils.InitializeValidator(), log)
+
+	ctx := utils.WithTenantId(context.Background(), "tenant-a")
+	if _, _, err := service.FindAll(ctx, dto.CustomerQueryFilter{Include: "vehicles"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 1 {
+		t.Fatalf("expected 1 query for 3 customers, got %d: %v", len(queries), queries)
+	}
+	if !strings.Contains(queries[0].sql, "cust_id = $1") {
+		t.Errorf("query is not scoped to the tenant: %s", queries[0].sql)
+	}
+	if len(queries[0].vars) == 0 || queries[0].vars[0] != "tenant-a" {
+		t.Errorf("query is not bound to the request tenant: %v", queries[0].vars)
+	}
+
+	queries = nil
+	if _, _, err := service.FindAll(context.Background(), dto.CustomerQueryFilter{Include: "vehicles"}); err != nil {
+		t.Errorf("listing without a tenant: %v", err)
+	}
+	if len(queries) != 0 {
+		t.Errorf("expected no query without a tenant, got %v", queries)
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/sync/singleflight"
+	"log/slog"
+	"scylla/dto"
+	"scylla/pkg/cache"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"time"
+)
+
+// DmsServiceCache decorates a DmsService with a read-through cache. Fresh
+// entries are served without calling Kong, concurrent misses for the same key
+// share one upstream call that outlives any single caller, and entries past
+// their TTL are kept for StaleTtl so they can still be served when
+// revalidation against Kong fails.
+type DmsServiceCache struct {
+	next     DmsService
+	store    cache.Store
+	metrics  *cache.Metrics
+	log      *slog.Logger
+	ttl      time.Duration
+	staleTtl time.Duration
+	group    singleflight.Group
+}
+
+func NewDmsServiceCache(next DmsService, store cache.Store, metrics *cache.Metrics, conf config.DmsCache, log *slog.Logger) DmsService {
+	return &DmsServiceCache{
+		next:     next,
+		store:    store,
+		metrics:  metrics,
+		log:      log,
+		ttl:      conf.Ttl,
+		staleTtl: conf.StaleTtl,
+	}
+}
+
+type cachedVehicleList struct {
+	Data []dto.VehicleResponse `json:"data"`
+	Meta dto.Meta              `json:"meta"`
+}
+
+func (service *DmsServiceCache) GetVehicle(ctx context.Context, dataFilter dto.VehicleQueryFilter) ([]dto.VehicleResponse, dto.Meta, error) {
+	key := fmt.Sprintf("vehicles:%+v", dataFilter)
+	result, err := cached(ctx, service, key, func(ctx context.Context) (cachedVehicleList, error) {
+		data, meta, err := service.next.GetVehicle(ctx, dataFilter)
+		return cachedVehicleList{Data: data, Meta: meta}, err
+	})
+	return result.Data, result.Meta, err
+}
+
+func (service *DmsServiceCache) GetVehicleById(ctx context.Context, request dto.VehicleParams) (dto.VehicleDetailResponse, error) {
+	key := fmt.Sprintf("vehicles:%d", request.VehicleId)
+	return cached(ctx, service, key, func(ctx context.Context) (dto.VehicleDetailResponse, error) {
+		return service.next.GetVehicleById(ctx, request)
+	})
+}
+
+func (service *DmsServiceCache) GetVehicleDriver(ctx context.Context, request dto.VehicleParams) (dto.DriverResponse, error) {
+	key := fmt.Sprintf("vehicles:%d:driver", request.VehicleId)
+	return cached(ctx, service, key, func(ctx context.Context) (dto.DriverResponse, error) {
+		return service.next.GetVehicleDriver(ctx, request)
+	})
+}
+
+func cached[T any](ctx context.Context, service *DmsServiceCache, key string, fetch func(ctx context.Context) (T, error)) (T, error) {
+	tenantId, ok := utils.GetTenantId(ctx)
+	if !ok {
+		// let the upstream service reject the request
+		return fetch(ctx)
+	}
+	key = fmt.Sprintf("dms:%s:%s", tenantId, key)
+
+	var stale *T
+	if entry, err := service.store.Get(ctx, key); err == nil {
+		var value T
+		if err := json.Unmarshal(entry.Value, &value); err == nil {
+			if entry.Age() < service.ttl {
+				service.metrics.Hits.Add(1)
+				return value, nil
+			}
+			stale = &value
+		}
+	}
+	service.metrics.Misses.Add(1)
+
+	// the call is shared by every coalesced caller, so it must not be cut
+	// short when the one that started it goes away
+	leader := false
+	call := service.group.DoChan(key, func() (interface{}, error) {
+		leader = true
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.Get().Runtime.UpstreamTimeout)
+		defer cancel()
+		value, err := fetch(fetchCtx)
+		if err != nil {
+			service.metrics.UpstreamErrors.Add(1)
+			return value, err
+		}
+		if body, err := json.Marshal(value); err == nil {
+			entry := cache.Entry{Value: body, StoredAt: time.Now()}
+			_ = service.store.Set(fetchCtx, key, entry, service.ttl+service.staleTtl)
+		}
+		return value, nil
+	})
+
+	var result interface{}
+	var err error
+	select {
+	case res := <-call:
+		result, err = res.Val, res.Err
+		if res.Shared && !leader {
+			service.metrics.Coalesced.Add(1)
+		}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		var notFound *exception.NotFoundErrorStruct
+		if stale != nil && !errors.As(err, &notFound) {
+			service.metrics.StaleServed.Add(1)
+			service.log.WarnContext(ctx, "dms unavailable, serving stale cache entry", slog.String("key", key), slog.String("error", err.Error()))
+			return *stale, nil
+		}
+		var zero T
+		return zero, err
+	}
+	return result.(T), nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"scylla/dto"
+	"scylla/pkg/exception"
+	"scylla/pkg/metrics"
+	"time"
+)
+
+// DmsServiceMetrics decorates the upstream DmsService with call counts and
+// latencies. It sits below the cache so only real calls to Kong are measured.
+type DmsServiceMetrics struct {
+	next DmsService
+}
+
+func NewDmsServiceMetrics(next DmsService) DmsService {
+	return &DmsServiceMetrics{next: next}
+}
+
+func (service *DmsServiceMetrics) GetVehicle(ctx context.Context, dataFilter dto.VehicleQueryFilter) ([]dto.VehicleResponse, dto.Meta, error) {
+	start := time.Now()
+	data, meta, err := service.next.GetVehicle(ctx, dataFilter)
+	observeDms("get_vehicle", start, err)
+	return data, meta, err
+}
+
+func (service *DmsServiceMetrics) GetVehicleById(ctx context.Context, request dto.VehicleParams) (dto.VehicleDetailResponse, error) {
+	start := time.Now()
+	response, err := service.next.GetVehicleById(ctx, request)
+	observeDms("get_vehicle_by_id", start, err)
+	return response, err
+}
+
+func (service *DmsServiceMetrics) GetVehicleDriver(ctx context.Context, request dto.VehicleParams) (dto.DriverResponse, error) {
+	start := time.Now()
+	response, err := service.next.GetVehicleDriver(ctx, request)
+	observeDms("get_vehicle_driver", start, err)
+	return response, err
+}
+
+func observeDms(operation string, start time.Time, err error) {
+	outcome := "ok"
+	var notFound *exception.NotFoundErrorStruct
+	switch {
+	case errors.As(err, &notFound):
+		outcome = "not_found"
+	case err != nil:
+		outcome = "error"
+	}
+	metrics.DmsRequests.WithLabelValues(operation, outcome).Inc()
+	metrics.DmsRequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -3,26 +3,36 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"scylla/dto"
 	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/tracing"
+	"scylla/pkg/utils"
+	"strconv"
 )
 
 type DmsService interface {
-	GetVehicle(ctx context.Context, dataFilter dto.GeneralQueryFilter) ([]dto.VehicleResponse, dto.Meta, error)
+	GetVehicle(ctx context.Context, dataFilter dto.VehicleQueryFilter) ([]dto.VehicleResponse, dto.Meta, error)
+	GetVehicleById(ctx context.Context, request dto.VehicleParams) (dto.VehicleDetailResponse, error)
+	GetVehicleDriver(ctx context.Context, request dto.VehicleParams) (dto.DriverResponse, error)
 }
 
-type DmsServiceImpl struct{}
-
-func NewDmsServiceImpl() DmsService {
-	return &DmsServiceImpl{}
+type DmsServiceImpl struct {
+	conf config.Kong
+	log  *slog.Logger
 }
 
-func (service *DmsServiceImpl) GetVehicle(ctx context.Context, dataFilter dto.GeneralQueryFilter) ([]dto.VehicleResponse, dto.Meta, error) {
-	config := config.Get()
+func NewDmsServiceImpl(conf config.Kong, log *slog.Logger) DmsService {
+	return &DmsServiceImpl{conf: conf, log: log}
+}
 
+func (service *DmsServiceImpl) GetVehicle(ctx context.Context, dataFilter dto.VehicleQueryFilter) ([]dto.VehicleResponse, dto.Meta, error) {
 	if dataFilter.Page == 0 {
 		dataFilter.Page = 1
 	}
@@ -31,43 +41,130 @@ func (service *DmsServiceImpl) GetVehicle(ctx context.Context, dataFilter dto.Ge
 		dataFilter.Limit = 10
 	}
 
-	endpointURL := fmt.Sprintf("%s/master/v1/vehicles?q=&page=%d&limit=%d&is_active=%d", config.Kong.Url, dataFilter.Page, dataFilter.Limit, dataFilter.IsActive)
-	req, err := http.NewRequestWithContext(ctx, "GET", endpointURL, nil)
-	if err != nil {
+	query := url.Values{}
+	query.Set("q", dataFilter.Query)
+	query.Set("page", strconv.Itoa(dataFilter.Page))
+	query.Set("limit", strconv.Itoa(dataFilter.Limit))
+	query.Set("is_active", strconv.Itoa(dataFilter.IsActive))
+	if dataFilter.Mode != "" {
+		query.Set("mode", dataFilter.Mode)
+	}
+	if dataFilter.Sort != "" {
+		query.Set("sort", dataFilter.Sort)
+	}
+	if dataFilter.VehicleTypeId != 0 {
+		query.Set("vehicle_type_id", strconv.FormatInt(dataFilter.VehicleTypeId, 10))
+	}
+	if dataFilter.DriverId != 0 {
+		query.Set("driver_id", strconv.FormatInt(dataFilter.DriverId, 10))
+	}
+
+	var response struct {
+		Data   []dto.VehicleResponse `json:"data"`
+		Paging dto.Paging            `json:"paging"`
+	}
+	if err := service.get(ctx, "/master/v1/vehicles", query, &response); err != nil {
 		return nil, dto.Meta{}, err
 	}
-	req.Header.Set("cust_id", "C220010001")
-	resp, err := http.DefaultClient.Do(req)
+
+	return response.Data, pagingToMeta(response.Paging), nil
+}
+
+func (service *DmsServiceImpl) GetVehicleById(ctx context.Context, request dto.VehicleParams) (dto.VehicleDetailResponse, error) {
+	var response struct {
+		Data dto.VehicleDetailResponse `json:"data"`
+	}
+	path := fmt.Sprintf("/master/v1/vehicles/%d", request.VehicleId)
+	if err := service.get(ctx, path, nil, &response); err != nil {
+		if errors.Is(err, exception.ErrNotFound) {
+			return dto.VehicleDetailResponse{}, exception.NewNotFoundHandler(fmt.Sprintf("vehicle %d not found", request.VehicleId)).WithCode(exception.CodeVehicleNotFound)
+		}
+		return dto.VehicleDetailResponse{}, err
+	}
+
+	return response.Data, nil
+}
+
+func (service *DmsServiceImpl) GetVehicleDriver(ctx context.Context, request dto.VehicleParams) (dto.DriverResponse, error) {
+	vehicle, err := service.GetVehicleById(ctx, request)
 	if err != nil {
-		return nil, dto.Meta{}, err
+		return dto.DriverResponse{}, err
+	}
+	if vehicle.DriverID == 0 {
+		return dto.DriverResponse{}, exception.NewNotFoundHandler("vehicle has no driver assigned").WithCode(exception.CodeDriverNotFound)
 	}
-	defer resp.Body.Close()
 
 	var response struct {
-		Data   []dto.VehicleResponse `json:"data"`
-		Paging struct {
-			TotalRecord int `json:"total_record"`
-			PageCurrent int `json:"page_current"`
-			PageLimit   int `json:"page_limit"`
-			PageTotal   int `json:"page_total"`
-		} `json:"paging"`
+		Data dto.DriverResponse `json:"data"`
+	}
+	path := fmt.Sprintf("/master/v1/drivers/%d", vehicle.DriverID)
+	if err := service.get(ctx, path, nil, &response); err != nil {
+		if errors.Is(err, exception.ErrNotFound) {
+			return dto.DriverResponse{}, exception.NewNotFoundHandler(fmt.Sprintf("driver %d not found", vehicle.DriverID)).WithCode(exception.CodeDriverNotFound)
+		}
+		return dto.DriverResponse{}, err
+	}
+
+	return response.Data, nil
+}
+
+// get calls the DMS endpoint behind Kong on behalf of the request tenant and
+// decodes the JSON body into out.
+func (service *DmsServiceImpl) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	custId, ok := utils.GetTenantId(ctx)
+	if !ok {
+		return exception.NewUnauthorizedHandler("tenant could not be resolved").WithCode(exception.CodeTenantRequired)
 	}
-	body, err := io.ReadAll(resp.Body)
 
+	ctx, cancel := context.WithTimeout(ctx, config.Get().Runtime.UpstreamTimeout)
+	defer cancel()
+
+	endpointURL := service.conf.Url + path
+	if len(query) > 0 {
+		endpointURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpointURL, nil)
 	if err != nil {
-		return nil, dto.Meta{}, err
+		return err
+	}
+	req.Header.Set("cust_id", custId)
+	req.Header.Set("X-Request-ID", utils.GetTraceId(ctx))
+	finish := tracing.StartClient(ctx, "dms", req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		finish(0, err)
+		service.log.WarnContext(ctx, "dms request failed", slog.String("path", path), slog.String("error", err.Error()))
+		return exception.NewBadGatewayHandler("dms is unavailable").WithCode(exception.CodeUpstreamError)
 	}
+	defer resp.Body.Close()
+	finish(resp.StatusCode, nil)
 
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, dto.Meta{}, err
+	// only a missing record is passed on to the client; any other non-2xx,
+	// including DMS refusing our own credentials, is the gateway's failure
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return exception.NewNotFoundHandler("record not found")
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		service.log.WarnContext(ctx, "dms request rejected", slog.String("path", path), slog.Int("status", resp.StatusCode))
+		return exception.NewBadGatewayHandler(fmt.Sprintf("dms responded with status %d", resp.StatusCode)).WithCode(exception.CodeUpstreamError)
 	}
 
-	pagination := &dto.Meta{
-		TotalData: response.Paging.TotalRecord,
-		Page:      response.Paging.PageCurrent,
-		Limit:     response.Paging.PageLimit,
-		TotalPage: response.Paging.PageTotal,
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return exception.NewBadGatewayHandler("dms response could not be read").WithCode(exception.CodeUpstreamError)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		service.log.WarnContext(ctx, "dms response invalid", slog.String("path", path), slog.String("error", err.Error()))
+		return exception.NewBadGatewayHandler("dms response could not be decoded").WithCode(exception.CodeUpstreamError)
 	}
+	return nil
+}
 
-	return response.Data, *pagination, nil
+func pagingToMeta(paging dto.Paging) dto.Meta {
+	return dto.Meta{
+		TotalData: &paging.TotalRecord,
+		Page:      paging.PageCurrent,
+		Limit:     paging.PageLimit,
+		TotalPage: &paging.PageTotal,
+	}
 }
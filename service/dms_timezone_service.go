@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"scylla/dto"
+	"scylla/pkg/utils"
+	"time"
+)
+
+// DmsServiceTimezone decorates DmsService so the timestamps DMS returns are
+// rendered as RFC 3339 in the request timezone like the rest of the API. It
+// sits above the cache, which keeps the upstream values, so one cached
+// response serves every timezone. Upstream times without an offset are read in
+// upstream.
+type DmsServiceTimezone struct {
+	next     DmsService
+	upstream *time.Location
+}
+
+func NewDmsServiceTimezone(next DmsService, upstream *time.Location) DmsService {
+	return &DmsServiceTimezone{next: next, upstream: upstream}
+}
+
+func (service *DmsServiceTimezone) GetVehicle(ctx context.Context, dataFilter dto.VehicleQueryFilter) ([]dto.VehicleResponse, dto.Meta, error) {
+	return service.next.GetVehicle(ctx, dataFilter)
+}
+
+func (service *DmsServiceTimezone) GetVehicleById(ctx context.Context, request dto.VehicleParams) (dto.VehicleDetailResponse, error) {
+	response, err := service.next.GetVehicleById(ctx, request)
+	if err != nil {
+		return response, err
+	}
+	loc := utils.GetLocation(ctx)
+	response.CreatedAt = service.format(response.CreatedAt, loc)
+	response.UpdatedAt = service.format(response.UpdatedAt, loc)
+	return response, nil
+}
+
+func (service *DmsServiceTimezone) GetVehicleDriver(ctx context.Context, request dto.VehicleParams) (dto.DriverResponse, error) {
+	response, err := service.next.GetVehicleDriver(ctx, request)
+	if err != nil {
+		return response, err
+	}
+	response.LicenseExpiredAt = service.format(response.LicenseExpiredAt, utils.GetLocation(ctx))
+	return response, nil
+}
+
+// format renders an upstream timestamp in loc. Dates and values it cannot
+// parse are passed through untouched rather than failing the request.
+func (service *DmsServiceTimezone) format(raw string, loc *time.Location) string {
+	date, err := utils.ParseDateTime(raw, service.upstream)
+	if err != nil || date.Day {
+		return raw
+	}
+	return utils.FormatTime(date.From, loc)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"scylla/dto"
+	"scylla/entity"
+	"scylla/pkg/config"
+	"scylla/pkg/exception"
+	"scylla/pkg/utils"
+	"scylla/repository"
+	"sync"
+	"time"
+)
+
+const (
+	SyncTriggerSchedule = "schedule"
+	SyncTriggerManual   = "manual"
+
+	syncStatusRunning = "running"
+	syncStatusSuccess = "success"
+	// syncStatusPartial runs upserted what they read but could not tell
+	// that they read everything, so nothing was deactivated.
+	syncStatusPartial = "partial"
+	syncStatusFailed  = "failed"
+)
+
+type VehicleSyncService interface {
+	Sync(ctx context.Context, trigger string) (dto.VehicleSyncRunResponse, error)
+	Run(ctx context.Context)
+}
+
+type VehicleSyncServiceImpl struct {
+	vehicleRepo repository.VehicleRepo
+	dmsService  DmsService
+	conf        config.VehicleSync
+	log         *slog.Logger
+	running     sync.Map
+}
+
+// NewVehicleSyncServiceImpl mirrors DMS vehicles into the local vehicles
+// table. dmsService should be the uncached client so every run sees the
+// upstream state.
+func NewVehicleSyncServiceImpl(vehicleRepo repository.VehicleRepo, dmsService DmsService, conf config.VehicleSync, log *slog.Logger) VehicleSyncService {
+	return &VehicleSyncServiceImpl{
+		vehicleRepo: vehicleRepo,
+		dmsService:  dmsService,
+		conf:        conf,
+		log:         log,
+	}
+}
+
+// Sync pages through the upstream vehicles of the request tenant, upserts them
+// and, when every page was read, marks vehicles no longer returned as
+// inactive. A run that cannot prove it saw every vehicle ends as partial
+// without deactivating anything. Each run is recorded in vehicle_sync_runs.
+func (service *VehicleSyncServiceImpl) Sync(ctx context.Context, trigger string) (response dto.VehicleSyncRunResponse, err error) {
+	custId, ok := utils.GetTenantId(ctx)
+	if !ok {
+		return response, exception.NewUnauthorizedHandler("tenant could not be resolved").WithCode(exception.CodeTenantRequired)
+	}
+
+	if _, busy := service.running.LoadOrStore(custId, struct{}{}); busy {
+		return response, exception.NewConflictHandler("vehicle sync is already running").WithCode(exception.CodeVehicleSyncRunning)
+	}
+	defer service.running.Delete(custId)
+
+	run := entity.VehicleSyncRun{
+		CustId:    custId,
+		Trigger:   trigger,
+		Status:    syncStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := service.vehicleRepo.InsertSyncRun(ctx, &run); err != nil {
+		return response, fmt.Errorf("record vehicle sync run: %w", err)
+	}
+
+	incomplete, syncErr := service.sync(ctx, &run)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = syncStatusSuccess
+	switch {
+	case syncErr != nil:
+		run.Status = syncStatusFailed
+		run.Error = syncErr.Error()
+	case incomplete != "":
+		run.Status = syncStatusPartial
+		run.Error = incomplete
+		service.log.WarnContext(ctx, "vehicle sync incomplete, skipped deactivation", slog.String("reason", incomplete))
+	}
+	// record the outcome even when the run was cut short by ctx
+	if err := service.vehicleRepo.UpdateSyncRun(context.WithoutCancel(ctx), run); err != nil {
+		return response, fmt.Errorf("record vehicle sync run: %w", err)
+	}
+
+	return dto.VehicleSyncRunResponseFromVehicleSyncRun(run, utils.GetLocation(ctx)), syncErr
+}
+
+// sync upserts every page and deactivates the vehicles it did not see. When
+// the paging upstream reports is missing or does not add up, it returns why
+// instead of deactivating, since a vehicle absent from a short read may still
+// exist.
+func (service *VehicleSyncServiceImpl) sync(ctx context.Context, run *entity.VehicleSyncRun) (incomplete string, err error) {
+	totalPage, totalData := 0, 0
+	for page := 1; ; page++ {
+		// is_active=1 so vehicles deactivated upstream drop out and get
+		// deactivated locally as well
+		data, paging, err := service.dmsService.GetVehicle(ctx, dto.VehicleQueryFilter{
+			Page:     page,
+			Limit:    service.conf.PageSize,
+			IsActive: 1,
+		})
+		if err != nil {
+			return "", err
+		}
+		run.Pages++
+		run.Fetched += len(data)
+
+		syncedAt := time.Now()
+		vehicles := make([]entity.Vehicle, 0, len(data))
+		for _, value := range data {
+			vehicles = append(vehicles, entity.Vehicle{
+				CustId:          run.CustId,
+				VehicleID:       value.VehicleID,
+				VehicleNo:       value.VehicleNo,
+				VehicleDesc:     value.VehicleDesc,
+				VehicleTypeName: value.VehicleType,
+				Length:          value.Length,
+				Width:           value.Width,
+				Height:          value.Height,
+				Volume:          value.Volume,
+				DriverID:        value.DriverID,
+				HelperID:        value.HelperID,
+				DriverName:      value.DriverName,
+				HelperName:      value.HelperName,
+				IsActive:        true,
+				SyncedAt:        syncedAt,
+				UpdatedAt:       syncedAt,
+			})
+		}
+
+		upserted, err := service.vehicleRepo.Upsert(ctx, vehicles)
+		if err != nil {
+			return "", err
+		}
+		run.Upserted += int(upserted)
+
+		pages, records := metaTotals(paging)
+		if page == 1 {
+			totalPage, totalData = pages, records
+			if totalPage <= 0 || totalData <= 0 {
+				return "dms returned no page count or total", nil
+			}
+		} else if pages != totalPage || records != totalData {
+			return fmt.Sprintf("dms totals changed during the run, from %d pages of %d vehicles to %d of %d", totalPage, totalData, pages, records), nil
+		}
+		if page >= totalPage {
+			break
+		}
+		if len(data) < service.conf.PageSize {
+			return fmt.Sprintf("page %d of %d returned %d vehicles, expected %d", page, totalPage, len(data), service.conf.PageSize), nil
+		}
+	}
+	if run.Fetched != totalData {
+		return fmt.Sprintf("fetched %d vehicles, dms reported %d", run.Fetched, totalData), nil
+	}
+
+	deactivated, err := service.vehicleRepo.DeactivateMissing(ctx, run.CustId, run.StartedAt)
+	if err != nil {
+		return "", err
+	}
+	run.Deactivated = int(deactivated)
+
+	return "", nil
+}
+
+// metaTotals returns the page count and total of meta, zero when missing.
+func metaTotals(meta dto.Meta) (pages, records int) {
+	if meta.TotalPage != nil {
+		pages = *meta.TotalPage
+	}
+	if meta.TotalData != nil {
+		records = *meta.TotalData
+	}
+	return pages, records
+}
+
+// Run syncs every configured tenant immediately and then on each interval
+// until ctx is done. It returns straight away when the interval is zero.
+func (service *VehicleSyncServiceImpl) Run(ctx context.Context) {
+	if service.conf.Interval <= 0 || len(service.conf.CustIds) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(service.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, custId := range service.conf.CustIds {
+			if ctx.Err() != nil {
+				return
+			}
+			tenantCtx := utils.WithTenantId(ctx, custId)
+			result, err := service.Sync(tenantCtx, SyncTriggerSchedule)
+			if err != nil {
+				service.log.ErrorContext(tenantCtx, "vehicle sync failed", slog.String("error", err.Error()))
+				continue
+			}
+			service.log.InfoContext(tenantCtx, "vehicle sync done",
+				slog.String("status", result.Status),
+				slog.Int("fetched", result.Fetched),
+				slog.Int("upserted", result.Upserted),
+				slog.Int("deactivated", result.Deactivated))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}